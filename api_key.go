@@ -2,26 +2,46 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"net/url"
+	"time"
 
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
 )
 
+// APIKeys iterates over every api key matching the filter, fetching pages as needed.
+func (c *Client) APIKeys(ctx context.Context, filter *model.APIKeyFilter) iter.Seq2[*model.APIKey, error] {
+	apiKeyIterator, _ := c.FindAPIKeysPaged(ctx, filter)
+
+	return apiKeyIterator.All()
+}
+
+// CreateAPIKey creates an API key. On success, apiKey is updated with the created key (including its ID and Key).
 func (c *Client) CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error {
 	body, err := c.marshal(map[string]*model.APIKey{"key": apiKey})
 	if err != nil {
 		return fmt.Errorf("marshal api key: %w", err)
 	}
 
-	if _, err = c.POST(ctx, "keys", body, nil); err != nil {
+	var response struct {
+		APIKey *model.APIKey `json:"key"`
+	}
+
+	if _, err = c.POST(withOperation(ctx, "CreateAPIKey"), "keys", body, &response); err != nil {
 		return fmt.Errorf("create api key: %w", err)
 	}
 
+	if response.APIKey != nil {
+		*apiKey = *response.APIKey
+	}
+
 	return nil
 }
 
 func (c *Client) DeleteAPIKey(ctx context.Context, apiKeyID string) error {
-	if _, err := c.DELETE(ctx, "keys/"+apiKeyID, nil); err != nil {
+	if _, err := c.DELETE(withOperation(ctx, "DeleteAPIKey"), "keys/"+apiKeyID, nil); err != nil {
 		return fmt.Errorf("delete api key: %w", err)
 	}
 
@@ -35,21 +55,117 @@ func (c *Client) FindAPIKeys(ctx context.Context, filter *model.APIKeyFilter) ([
 		APIKeys []*model.APIKey `json:"keys"`
 	}
 
-	if _, err := c.GET(ctx, "keys?"+query, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindAPIKeys"), "keys?"+query, &response); err != nil {
 		return nil, fmt.Errorf("find api keys: %w", err)
 	}
 
 	return response.APIKeys, nil
 }
 
+func (c *Client) FindAPIKeysPaged(ctx context.Context, filter *model.APIKeyFilter) (*Iterator[*model.APIKey], error) {
+	fetch := func(ctx context.Context, pageToken string) ([]*model.APIKey, string, error) {
+		q := structToQueryParams(filter)
+		if pageToken != "" {
+			q += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var resp struct {
+			APIKeys       []*model.APIKey `json:"keys"`
+			NextPageToken string          `json:"nextPageToken"`
+		}
+
+		if _, err := c.GET(withOperation(ctx, "FindAPIKeysPaged"), "keys?"+q, &resp); err != nil {
+			return nil, "", fmt.Errorf("find api keys: %w", err)
+		}
+
+		return resp.APIKeys, resp.NextPageToken, nil
+	}
+
+	return newIterator(ctx, fetch), nil
+}
+
 func (c *Client) FindAPIKeyByID(ctx context.Context, id string) (*model.APIKey, error) {
 	var response struct {
 		APIKey *model.APIKey `json:"key"`
 	}
 
-	if _, err := c.GET(ctx, "keys/"+id, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindAPIKeyByID"), "keys/"+id, &response); err != nil {
 		return nil, fmt.Errorf("find api key: %w", err)
 	}
 
 	return response.APIKey, nil
 }
+
+// RotateAPIKey replaces the API key with the given ID. It creates a new key with the same description, environment,
+// owner and lifetime, checks that the new key works by calling FindSessionUser with it, and passes it to save (if set),
+// e.g. to persist it to a config file. Only then is the old key deleted (using the new key). If any step before the
+// deletion fails, the new key is deleted again and the old one is left untouched.
+//
+// The client itself keeps using its current key, so if that's the key being rotated, call SetAPIKey with the returned
+// key's Key afterwards.
+func (c *Client) RotateAPIKey(ctx context.Context, id string, save func(apiKey *model.APIKey) error) (*model.APIKey, error) {
+	oldKey, err := c.FindAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	newKey := &model.APIKey{
+		Description: oldKey.Description,
+		Environment: oldKey.Environment,
+		UserID:      oldKey.UserID,
+	}
+
+	// Keep the same lifetime as the old key.
+	if !oldKey.Expiry.IsZero() && !oldKey.Created.IsZero() {
+		newKey.Expiry = time.Now().Add(oldKey.Expiry.Sub(oldKey.Created))
+	}
+
+	if err = c.CreateAPIKey(ctx, newKey); err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	rotated := *c
+	rotated.SetAPIKey(newKey.Key)
+
+	// Clean up the new key if it can't be put into use.
+	abort := func(err error) (*model.APIKey, error) {
+		if dErr := c.DeleteAPIKey(ctx, newKey.ID); dErr != nil {
+			err = errors.Join(err, fmt.Errorf("remove new key %s: %w", newKey.ID, dErr))
+		}
+
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	if _, err = rotated.FindSessionUser(ctx); err != nil {
+		return abort(fmt.Errorf("verify new key: %w", err))
+	}
+
+	if save != nil {
+		if err = save(newKey); err != nil {
+			return abort(fmt.Errorf("save new key: %w", err))
+		}
+	}
+
+	if err = rotated.DeleteAPIKey(ctx, oldKey.ID); err != nil {
+		return newKey, fmt.Errorf("rotate api key: delete old key: %w", err)
+	}
+
+	return newKey, nil
+}
+
+func (c *Client) UpdateAPIKey(ctx context.Context, id string, update *model.APIKeyUpdate) (*model.APIKey, error) {
+	body, err := c.marshal(map[string]*model.APIKeyUpdate{"key": update})
+	if err != nil {
+		return nil, fmt.Errorf("marshal update: %w", err)
+	}
+
+	var response struct {
+		APIKey *model.APIKey `json:"key"`
+	}
+
+	if _, err = c.PATCH(withOperation(ctx, "UpdateAPIKey"), "keys/"+id, body, &response); err != nil {
+		return nil, fmt.Errorf("update api key: %w", err)
+	}
+
+	return response.APIKey, nil
+}
@@ -3,10 +3,31 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
 )
 
+// FindPasswordResetTokens returns the outstanding password reset tokens matching the filter. Admin only.
+func (c *Client) FindPasswordResetTokens(ctx context.Context, filter *model.PasswordResetTokenFilter) ([]*model.PasswordResetToken, error) {
+	query := structToQueryParams(filter)
+
+	// The generated filter has no query tag for the token.
+	if filter != nil && filter.Token != "" {
+		query += "&token=" + url.QueryEscape(filter.Token)
+	}
+
+	var response struct {
+		Tokens []*model.PasswordResetToken `json:"tokens"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindPasswordResetTokens"), "auth/reset?"+query, &response); err != nil {
+		return nil, fmt.Errorf("find password reset tokens: %w", err)
+	}
+
+	return response.Tokens, nil
+}
+
 func (c *Client) Login(ctx context.Context, email string, password string) (*model.APIKey, error) {
 	body, err := c.marshal(map[string]*model.Login{"login": {Email: email, Password: password}})
 	if err != nil {
@@ -17,9 +38,37 @@ func (c *Client) Login(ctx context.Context, email string, password string) (*mod
 		Key *model.APIKey `json:"key"`
 	}
 
-	if _, err = c.POST(ctx, "auth/login", body, &response); err != nil {
+	if _, err = c.POST(withOperation(ctx, "Login"), "auth/login", body, &response); err != nil {
 		return nil, fmt.Errorf("login: %w", err)
 	}
 
 	return response.Key, nil
 }
+
+// RequestPasswordReset asks the API to email a password reset token to the given address. It doesn't need an API key.
+func (c *Client) RequestPasswordReset(ctx context.Context, email string) error {
+	body, err := c.marshal(map[string]map[string]string{"reset": {"email": email}})
+	if err != nil {
+		return fmt.Errorf("marshal password reset request: %w", err)
+	}
+
+	if _, err = c.POST(withOperation(ctx, "RequestPasswordReset"), "auth/reset", body, nil); err != nil {
+		return fmt.Errorf("request password reset: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset. It doesn't need an API key.
+func (c *Client) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	body, err := c.marshal(map[string]map[string]string{"reset": {"password": newPassword}})
+	if err != nil {
+		return fmt.Errorf("marshal password reset: %w", err)
+	}
+
+	if _, err = c.POST(withOperation(ctx, "ResetPassword"), "auth/reset/"+url.PathEscape(token), body, nil); err != nil {
+		return fmt.Errorf("reset password: %w", err)
+	}
+
+	return nil
+}
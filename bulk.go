@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+const (
+	DefaultBulkBatchSize   = 500
+	DefaultBulkConcurrency = 4
+	DefaultBulkRetries     = 3
+
+	bulkRetryWait = time.Second
+)
+
+type (
+	// BatchError describes a batch that still failed after every retry.
+	BatchError struct {
+		Err     error
+		Domains []*model.DomainSubmission
+		Index   int
+	}
+
+	// BulkCreateResult is the combined report of a BulkCreateDomains call.
+	BulkCreateResult struct {
+		// Errors holds the per-domain errors returned by the API for the batches that were accepted.
+		Errors []*model.DomainError
+
+		// Failed holds the batches that couldn't be submitted at all.
+		Failed []*BatchError
+
+		// Submitted is the number of domains in batches the API accepted.
+		Submitted int
+	}
+
+	// BulkOption is a function that applies a configuration option to BulkCreateDomains.
+	BulkOption func(*bulkOptions)
+
+	bulkOptions struct {
+		progress    func(done, total int)
+		batchSize   int
+		concurrency int
+		retries     int
+	}
+)
+
+// Error returns the underlying error, prefixed with the batch it belongs to.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch %d (%d domains): %v", e.Index, len(e.Domains), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// WithBatchConcurrency sets how many batches are submitted at once.
+func WithBatchConcurrency(concurrency int) BulkOption {
+	return func(o *bulkOptions) {
+		if concurrency > 0 {
+			o.concurrency = concurrency
+		}
+	}
+}
+
+// WithBatchProgress sets a callback that's invoked after each batch completes (successfully or not), with the number of
+// domains processed so far and the total. It may be called from multiple goroutines, but never concurrently.
+func WithBatchProgress(progress func(done, total int)) BulkOption {
+	return func(o *bulkOptions) {
+		o.progress = progress
+	}
+}
+
+// WithBatchRetries sets how many times a failed batch is retried before it's reported as failed.
+func WithBatchRetries(retries int) BulkOption {
+	return func(o *bulkOptions) {
+		if retries >= 0 {
+			o.retries = retries
+		}
+	}
+}
+
+// WithBatchSize sets the maximum number of domains sent in a single request.
+func WithBatchSize(size int) BulkOption {
+	return func(o *bulkOptions) {
+		if size > 0 {
+			o.batchSize = size
+		}
+	}
+}
+
+// BulkCreateDomains submits domains in batches (DefaultBulkBatchSize by default), sending up to DefaultBulkConcurrency
+// batches at once. Failed batches are retried with exponential backoff, unless the API rejected the request outright
+// (e.g. a 400 or 401); a batch that's too large for the server (413) is split in half and retried. Each attempt at a
+// batch (including the client's own retries, see WithRetryPolicy) carries the same Idempotency-Key, so a batch is
+// never stored twice.
+//
+// The per-domain errors from every batch are collected into a single result. If any batch still failed, the returned
+// error joins their errors, and the result's Failed field holds the affected domains so they can be resubmitted.
+func (c *Client) BulkCreateDomains(ctx context.Context, domains []*model.DomainSubmission, opts ...BulkOption) (*BulkCreateResult, error) {
+	options := bulkOptions{
+		batchSize:   DefaultBulkBatchSize,
+		concurrency: DefaultBulkConcurrency,
+		retries:     DefaultBulkRetries,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var (
+		result BulkCreateResult
+		done   int
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+	)
+
+	batches := make(chan *BatchError)
+
+	for range min(options.concurrency, (len(domains)+options.batchSize-1)/options.batchSize) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				domainErrs, failed := c.createDomainBatch(ctx, batch, options.retries)
+
+				mu.Lock()
+
+				result.Errors = append(result.Errors, domainErrs...)
+				result.Failed = append(result.Failed, failed...)
+				result.Submitted += len(batch.Domains)
+
+				for _, f := range failed {
+					result.Submitted -= len(f.Domains)
+				}
+
+				done += len(batch.Domains)
+				if options.progress != nil {
+					options.progress(done, len(domains))
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < len(domains); i += options.batchSize {
+		batch := &BatchError{Domains: domains[i:min(i+options.batchSize, len(domains))], Index: i / options.batchSize}
+
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+			batch.Err = ctx.Err()
+
+			mu.Lock()
+			result.Failed = append(result.Failed, batch)
+			mu.Unlock()
+		}
+	}
+
+	close(batches)
+	wg.Wait()
+
+	if len(result.Failed) == 0 {
+		return &result, nil
+	}
+
+	errs := make([]error, len(result.Failed))
+	for i, batch := range result.Failed {
+		errs[i] = batch
+	}
+
+	return &result, fmt.Errorf("bulk create domains: %w", errors.Join(errs...))
+}
+
+// createDomainBatch submits a single batch, retrying transient failures and splitting batches the server says are
+// too large. It returns the per-domain errors for the domains that were accepted, and whatever couldn't be submitted.
+func (c *Client) createDomainBatch(ctx context.Context, batch *BatchError, retries int) ([]*model.DomainError, []*BatchError) {
+	var err error
+
+	// Every attempt at this batch is the same write, so it's sent under the same idempotency key: if the API committed
+	// an attempt whose response was lost, the retry is recognized instead of being stored twice.
+	ctx = withIdempotencyKey(ctx, newUUID())
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				batch.Err = fmt.Errorf("wait to retry batch: %w", ctx.Err())
+				return nil, []*BatchError{batch}
+			case <-time.After(bulkRetryWait << (attempt - 1)):
+			}
+		}
+
+		var domainErrs []*model.DomainError
+
+		domainErrs, err = c.CreateDomains(ctx, batch.Domains...)
+		if err == nil {
+			return domainErrs, nil
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if apiErr.StatusCode == http.StatusRequestEntityTooLarge && len(batch.Domains) > 1 {
+				half := len(batch.Domains) / 2 //nolint:mnd // Split the batch in half.
+
+				firstErrs, firstFailed := c.createDomainBatch(ctx, &BatchError{Domains: batch.Domains[:half], Index: batch.Index}, retries)
+				secondErrs, secondFailed := c.createDomainBatch(ctx, &BatchError{Domains: batch.Domains[half:], Index: batch.Index}, retries)
+
+				return append(firstErrs, secondErrs...), append(firstFailed, secondFailed...)
+			}
+
+			// Retrying won't help if the request itself was refused.
+			if apiErr.StatusCode < http.StatusInternalServerError && apiErr.StatusCode != http.StatusTooManyRequests &&
+				apiErr.StatusCode != http.StatusRequestTimeout {
+				break
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	batch.Err = err
+
+	return nil, []*BatchError{batch}
+}
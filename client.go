@@ -3,7 +3,9 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/fxamacker/cbor/v2"
@@ -19,21 +21,30 @@ const (
 
 // Client represents the Domain Trust API client.
 type Client struct {
-	apiKey       string
-	client       *retryablehttp.Client
-	contentType  string
-	debug        bool
-	encodingType string
+	apiKey           string
+	baseURL          string
+	client           *retryablehttp.Client
+	contentType      string
+	debug            bool
+	encodingType     string
+	logger           *slog.Logger
+	middlewares      []Middleware
+	normalizeDomains bool
+	rateLimitHook    func(RateLimitEvent)
+	rateLimiter      *rateLimiter
+	retryPolicy      RetryPolicy
 }
 
 // New initializes a new Domain Trust API client using the provided API key and options.
 func New(apiKey string, opts ...Option) *Client {
 	httpClient := retryablehttp.NewClient()
+	httpClient.ErrorHandler = retryablehttp.PassthroughErrorHandler // Surface the final response so we can return an *APIError.
 	httpClient.Logger = nil
 	httpClient.HTTPClient.Timeout = DefaultTimeout
 
 	c := &Client{
 		apiKey:       apiKey,
+		baseURL:      EndpointURL,
 		client:       httpClient,
 		contentType:  ContentTypeCBOR,
 		debug:        false,
@@ -44,6 +55,19 @@ func New(apiKey string, opts ...Option) *Client {
 		opt(c)
 	}
 
+	c.retryPolicy.apply(c.client)
+
+	if c.logger == nil {
+		c.logger = newLogger(c.debug)
+	}
+
+	c.client.RequestLogHook = c.logRetry
+
+	if c.rateLimiter != nil {
+		c.rateLimiter.hook = c.rateLimitHook
+		c.client.HTTPClient = c.rateLimiter.wrap(c.client.HTTPClient)
+	}
+
 	return c
 }
 
@@ -60,6 +84,17 @@ func (c *Client) SetTimeout(timeout time.Duration) {
 // Option is a function that applies a configuration option to a Client.
 type Option func(*Client)
 
+// WithBaseURL overrides the default API endpoint (e.g. to target staging, a self-hosted instance, or a local mock).
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL == "" {
+			baseURL = EndpointURL
+		}
+
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
 // WithClient allows providing a custom *http.Client.
 func WithClient(client *http.Client) Option {
 	return func(c *Client) {
@@ -82,13 +117,22 @@ func WithContentType(contentType string) Option {
 	}
 }
 
-// WithDebug enables or disables debug mode.
+// WithDebug enables or disables debug mode, which logs every request and response to stderr (see WithLogger).
 func WithDebug(debug bool) Option {
 	return func(c *Client) {
 		c.debug = debug
 	}
 }
 
+// WithDomainNormalization makes CreateDomains (and BulkCreateDomains) normalize each submission before sending it: the
+// domain is cleaned up with domainutil.Normalize, and its SLD, TLD, RootDomain and Subdomain are filled in. Submissions
+// whose domain can't be normalized are reported as domain errors instead of being sent.
+func WithDomainNormalization(enabled bool) Option {
+	return func(c *Client) {
+		c.normalizeDomains = enabled
+	}
+}
+
 // WithEncodingType overrides the default encoding type from ZSTD to a user-specified value.
 func WithEncodingType(encodingType string) Option {
 	return func(c *Client) {
@@ -105,6 +149,7 @@ func WithTimeout(timeout time.Duration) Option {
 	return func(c *Client) {
 		if c.client == nil {
 			c.client = retryablehttp.NewClient()
+			c.client.ErrorHandler = retryablehttp.PassthroughErrorHandler
 		}
 
 		c.client.HTTPClient.Timeout = timeout
@@ -20,6 +20,8 @@ func newAPIKeysCMD() *cobra.Command {
 	cmd.AddCommand(newAPIKeysDeleteCMD())
 	cmd.AddCommand(newAPIKeysFindCMD())
 	cmd.AddCommand(newAPIKeysGetCMD())
+	cmd.AddCommand(newAPIKeysRotateCMD())
+	cmd.AddCommand(newAPIKeysUpdateCMD())
 
 	return cmd
 }
@@ -28,14 +30,23 @@ func newAPIKeysCreateCMD() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "create",
 		Short:   "Create api key",
-		Example: "  client apikeys create\n  client apikeys create --email=dev@gcai.dev",
+		Example: "  client apikeys create --expiry=2027-01-01\n  client apikeys create --expiry=2027-01-01 --description=\"CI pipeline\" --environment=testing",
 		Args:    cobra.ExactArgs(0),
 		PreRun:  adminCheck,
 		Run: func(cmd *cobra.Command, _ []string) {
-			var apiKey *model.APIKey
+			apiKey := &model.APIKey{}
 
 			cmd.Flags().Visit(func(flag *pflag.Flag) {
 				switch flag.Name {
+				case "description":
+					apiKey.Description = flag.Value.String()
+				case "environment":
+					environment, err := parseEnum(flag.Value.String(), []string{model.APIKeyEnvironmentProduction, model.APIKeyEnvironmentTesting})
+					if err != nil {
+						log.Fatal().Err(err).Msg("Invalid environment")
+					}
+
+					apiKey.Environment = environment
 				case "expiry":
 					expiry, err := cast.StringToDate(flag.Value.String())
 					if err != nil {
@@ -64,8 +75,10 @@ func newAPIKeysCreateCMD() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().String("description", "", "Set description")
+	cmd.Flags().String("environment", "", "Set environment (production|testing)")
 	cmd.Flags().String("expiry", "", "Set expiry date (e.g. "+time.Now().Format("2006-01-02")+")")
-	cmd.Flags().Int64("userID", 0, "Set user id")
+	cmd.Flags().String("userID", "", "Set user id")
 	_ = markFlagsRequired(cmd, "expiry")
 
 	return cmd
@@ -116,7 +129,7 @@ func newAPIKeysFindCMD() *cobra.Command {
 	}
 
 	// Environment.
-	cmd.Flags().String("environment", "", "Which environment your requests should be made against (production|test)")
+	cmd.Flags().String("environment", "", "Which environment your requests should be made against (production|testing)")
 	cmd.Flags().String("expiryAfter", "", "Filter for API keys which expire after the given timestamp (e.g. 2022-08-30T00:00:00.001Z)")
 	cmd.Flags().String("expiryBefore", "", "Filter for API keys which expire before the given timestamp (e.g. 2022-08-30T00:00:00.001Z)")
 	cmd.Flags().String("userID", "", "Filter for API keys belonging to a specific user")
@@ -142,3 +155,92 @@ func newAPIKeysGetCMD() *cobra.Command {
 
 	return cmd
 }
+
+func newAPIKeysRotateCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace an api key with a new one",
+		Long: "Create a new api key with the same description, environment and lifetime, verify it works, save it to " +
+			"the config and then delete the old key. If anything fails before the old key is deleted, it stays valid.",
+		Example: "  client apiKeys rotate :id\n  client apiKeys rotate 19ad4d0e-569d-4ee7-9ee5-c24594a1acd9",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			save, err := cmd.Flags().GetBool("save")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to parse save flag")
+			}
+
+			var saveKey func(*model.APIKey) error
+			if save {
+				saveKey = func(apiKey *model.APIKey) error {
+					cfg.APIKey = apiKey.Key
+					return cfg.Save()
+				}
+			}
+
+			apiKey, err := apiClient.RotateAPIKey(cmd.Context(), args[0], saveKey)
+			if err != nil {
+				if apiKey == nil {
+					log.Fatal().Err(err).Msg("Failed to rotate api key")
+				}
+
+				log.Error().Err(err).Msg("Rotated api key, but failed to delete the old one")
+			}
+
+			if save {
+				log.Info().Msg("Saved new api key to config")
+			}
+
+			printToConsole(apiKey)
+		},
+	}
+
+	cmd.Flags().Bool("save", true, "Save the new key to the config (disable when rotating another user's key)")
+
+	return cmd
+}
+
+func newAPIKeysUpdateCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "update",
+		Short:   "Update api key",
+		Example: "  client apiKeys update :id --description=\"CI pipeline\"\n  client apiKeys update 19ad4d0e-569d-4ee7-9ee5-c24594a1acd9 --expiry=2027-01-01",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			var update model.APIKeyUpdate
+
+			cmd.Flags().Visit(func(flag *pflag.Flag) {
+				val := flag.Value.String()
+				switch flag.Name {
+				case "description":
+					update.Description = &val
+				case "expiry":
+					expiry, err := cast.StringToDate(val)
+					if err != nil {
+						log.Fatal().Err(err).Msg("Failed to parse expiry date")
+					}
+
+					if expiry.Before(time.Now()) {
+						log.Fatal().Msg("Expiry can't be in the past")
+					}
+
+					update.Expiry = &expiry
+				}
+			})
+
+			apiKey, err := apiClient.UpdateAPIKey(cmd.Context(), args[0], &update)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to update api key")
+			}
+
+			printToConsole(apiKey)
+		},
+	}
+
+	cmd.Flags().String("description", "", "Update api key's description")
+	cmd.Flags().String("expiry", "", "Update api key's expiry date (e.g. "+time.Now().Format("2006-01-02")+")")
+
+	return cmd
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newAuthCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Recover account access",
+	}
+
+	cmd.AddCommand(newAuthResetCMD())
+	cmd.AddCommand(newAuthResetRequestCMD())
+	cmd.AddCommand(newAuthTokensCMD())
+
+	return cmd
+}
+
+func newAuthResetCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "reset",
+		Short:   "Set a new password using a password reset token",
+		Long:    "Set a new password using the token emailed by \"auth reset-request\". The new password is read from the terminal (or from stdin, when it's piped).",
+		Example: "  client auth reset :token\n  client auth reset 3f1c9a0e8b7d4c2a",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			password, err := readNewPassword()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read password")
+			}
+
+			if err = apiClient.ResetPassword(cmd.Context(), args[0], password); err != nil {
+				log.Fatal().Err(err).Msg("Failed to reset password")
+			}
+
+			// Don't keep logging in with the old password.
+			if cfg.UserPass != "" {
+				cfg.UserPass = ""
+
+				if err = cfg.Save(); err != nil {
+					log.Fatal().Err(err).Msg("could not save config")
+				}
+			}
+
+			log.Info().Msg("Password reset successfully! Run \"client login\" to log in with your new password.")
+		},
+	}
+
+	return cmd
+}
+
+func newAuthResetRequestCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "reset-request",
+		Short:   "Email a password reset token to your account",
+		Example: "  client auth reset-request\n  client auth reset-request dev@gcai.dev",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			email := cfg.UserEmail
+			if len(args) > 0 {
+				email = args[0]
+			}
+
+			if email == "" {
+				log.Fatal().Msg("No email provided")
+			}
+
+			if err := apiClient.RequestPasswordReset(cmd.Context(), email); err != nil {
+				log.Fatal().Err(err).Msg("Failed to request password reset")
+			}
+
+			log.Info().Msg("If " + email + " belongs to an account, a password reset token has been sent to it. Use it with \"client auth reset :token\".")
+		},
+	}
+
+	return cmd
+}
+
+func newAuthTokensCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "tokens",
+		Short:  "Find outstanding password reset tokens",
+		Args:   cobra.ExactArgs(0),
+		PreRun: adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var filter model.PasswordResetTokenFilter
+
+			if err := unmarshalFlags(cmd, &filter); err != nil {
+				log.Fatal().Err(err).Msg("Failed to unmarshal flags")
+			}
+
+			tokens, err := apiClient.FindPasswordResetTokens(cmd.Context(), &filter)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to find password reset tokens")
+			}
+
+			if len(tokens) == 0 {
+				log.Warn().Msg("No password reset tokens found")
+				return
+			}
+
+			printToConsole(tokens)
+		},
+	}
+
+	cmd.Flags().String("token", "", "Filter for a specific token")
+
+	return cmd
+}
+
+// readNewPassword prompts for a new password twice without echoing it. When stdin isn't a terminal, the password is
+// read from its first line instead, so it can be piped in.
+func readNewPassword() (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read password from stdin: %w", err)
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return "", errors.New("no password provided")
+		}
+
+		return line, nil
+	}
+
+	password, err := promptPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if password == "" {
+		return "", errors.New("no password provided")
+	}
+
+	confirmation, err := promptPassword("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if password != confirmation {
+		return "", errors.New("passwords don't match")
+	}
+
+	return password, nil
+}
+
+func promptPassword(prompt string) (string, error) {
+	_, _ = fmt.Fprint(os.Stderr, prompt)
+
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	_, _ = fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	return string(password), nil
+}
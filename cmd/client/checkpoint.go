@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// checkpoint records how far a paginated export got, so it can be resumed later.
+type checkpoint struct {
+	Updated   time.Time       `json:"updated"`
+	Filter    json.RawMessage `json:"filter"`
+	Format    string          `json:"format"`
+	Output    string          `json:"output,omitempty"`
+	PageToken string          `json:"pageToken"`
+	Items     uint64          `json:"items"`
+
+	// Offset is the size of the output file when the checkpoint was saved. Anything written after it (a partial
+	// record, or records from a page that'll be fetched again) is truncated when resuming.
+	Offset int64 `json:"offset,omitempty"`
+}
+
+// loadCheckpoint reads a checkpoint from disk. A missing file returns a nil checkpoint and no error.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil //nolint:nilnil // A missing checkpoint isn't an error.
+		}
+
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err = json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("unmarshal checkpoint: %w", err)
+	}
+
+	return &cp, nil
+}
+
+// matches reports whether the checkpoint was created for the given filter.
+func (cp *checkpoint) matches(filter any) (bool, error) {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return false, fmt.Errorf("marshal filter: %w", err)
+	}
+
+	// The saved filter may have been re-indented when the checkpoint was written.
+	var saved bytes.Buffer
+	if err = json.Compact(&saved, cp.Filter); err != nil {
+		return false, fmt.Errorf("compact saved filter: %w", err)
+	}
+
+	return bytes.Equal(saved.Bytes(), data), nil
+}
+
+// save atomically writes the checkpoint to disk.
+func (cp *checkpoint) save(path string, filter any) error {
+	filterData, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Errorf("marshal filter: %w", err)
+	}
+
+	cp.Filter = filterData
+	cp.Updated = time.Now().UTC()
+
+	data, err := json.MarshalIndent(cp, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+
+	if err = os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -18,6 +20,7 @@ func newConfigCMD() *cobra.Command {
 	}
 
 	cmd.AddCommand(newConfigGetCMD())
+	cmd.AddCommand(newConfigProfilesCMD())
 	cmd.AddCommand(newConfigSetCMD())
 	cmd.AddCommand(newConfigShowCMD())
 
@@ -34,6 +37,8 @@ func newConfigGetCMD() *cobra.Command {
 			switch strings.ToLower(args[0]) {
 			case "apikey":
 				printToConsole("api key: " + cfg.APIKey)
+			case "endpoint":
+				printToConsole("endpoint: " + cfg.Endpoint)
 			case "useremail":
 				printToConsole("user email: " + cfg.UserEmail)
 			case "userpass":
@@ -49,16 +54,38 @@ func newConfigGetCMD() *cobra.Command {
 	return cmd
 }
 
+func newConfigProfilesCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profiles",
+		Short:   "List the named config profiles",
+		Example: "  client config profiles\n  client --profile=staging config set endpoint https://staging.example.org/api",
+		Args:    cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			profiles := slices.Sorted(maps.Keys(cfg.Profiles))
+			if len(profiles) == 0 {
+				log.Warn().Msg("No profiles configured")
+				return
+			}
+
+			printToConsole(profiles)
+		},
+	}
+
+	return cmd
+}
+
 func newConfigSetCMD() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "set",
 		Short:   "Set a config value",
-		Example: "  client config set apikey 019a0dd4-11a5-7477-91a8-538b1bc334e4",
+		Example: "  client config set apikey 019a0dd4-11a5-7477-91a8-538b1bc334e4\n  client --profile=staging config set endpoint https://staging.example.org/api",
 		Args:    cobra.ExactArgs(2), //nolint:mnd // Unnecessary.
 		Run: func(_ *cobra.Command, args []string) {
 			switch strings.ToLower(args[0]) {
 			case "apikey":
 				cfg.APIKey = args[1]
+			case "endpoint":
+				cfg.Endpoint = args[1]
 			case "useremail":
 				cfg.UserEmail = args[1]
 			case "userpass":
@@ -94,23 +121,38 @@ func newConfigShowCMD() *cobra.Command {
 	return cmd
 }
 
-type Config struct {
-	dir       string
-	path      string
-	APIKey    string `json:"apiKey" yaml:"apiKey"`
-	UserEmail string `json:"userEmail" yaml:"userEmail"`
-	UserPass  string `json:"userPass,omitempty" yaml:"userPass,omitempty"`
-	UserRole  string `json:"userRole" yaml:"userRole"`
-}
+type (
+	Config struct {
+		ConfigProfile `yaml:",inline"`
+
+		dir      string
+		path     string
+		defaults ConfigProfile
+		profile  string
+		Profiles map[string]*ConfigProfile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	}
+
+	// ConfigProfile holds the connection details for a single environment.
+	ConfigProfile struct {
+		APIKey    string `json:"apiKey" yaml:"apiKey"`
+		Endpoint  string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+		UserEmail string `json:"userEmail" yaml:"userEmail"`
+		UserPass  string `json:"userPass,omitempty" yaml:"userPass,omitempty"`
+		UserRole  string `json:"userRole" yaml:"userRole"`
+	}
+)
 
 func newConfig(directory string) (*Config, error) {
 	config := Config{
-		dir:       directory,
-		path:      directory + slash + "config.yml",
-		APIKey:    "",
-		UserEmail: "",
-		UserPass:  "",
-		UserRole:  "",
+		dir:  directory,
+		path: directory + slash + "config.yml",
+		ConfigProfile: ConfigProfile{
+			APIKey:    "",
+			Endpoint:  "",
+			UserEmail: "",
+			UserPass:  "",
+			UserRole:  "",
+		},
 	}
 
 	if err := config.Load(); err != nil {
@@ -145,8 +187,24 @@ func (c *Config) Load() error {
 	return nil
 }
 
+// Save writes the config to disk. When a profile is active, its values are stored under that profile, leaving the
+// top-level (default) values untouched.
 func (c *Config) Save() error {
-	configData, err := yaml.Marshal(c)
+	out := *c
+
+	if c.profile != "" {
+		profile := c.ConfigProfile
+
+		out.ConfigProfile = c.defaults
+		out.Profiles = maps.Clone(c.Profiles)
+		if out.Profiles == nil {
+			out.Profiles = make(map[string]*ConfigProfile, 1)
+		}
+
+		out.Profiles[c.profile] = &profile
+	}
+
+	configData, err := yaml.Marshal(out)
 	if err != nil {
 		return fmt.Errorf("marshal config values: %w", err)
 	}
@@ -157,3 +215,28 @@ func (c *Config) Save() error {
 
 	return nil
 }
+
+// UseProfile switches the active values to the named profile. Unknown profiles are an error, unless create is set, in
+// which case they start out empty and are created on the next Save.
+func (c *Config) UseProfile(name string, create bool) error {
+	if name == "" || name == c.profile {
+		return nil
+	}
+
+	if _, ok := c.Profiles[name]; !ok && !create {
+		return fmt.Errorf("unknown profile %q (see config profiles)", name)
+	}
+
+	if c.profile == "" {
+		c.defaults = c.ConfigProfile
+	}
+
+	c.profile = name
+	c.ConfigProfile = ConfigProfile{}
+
+	if profile, ok := c.Profiles[name]; ok && profile != nil {
+		c.ConfigProfile = *profile
+	}
+
+	return nil
+}
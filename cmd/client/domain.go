@@ -1,17 +1,20 @@
 package main
 
 import (
-	"bufio"
-	"encoding/csv"
+	"context"
 	"errors"
-	"fmt"
-	"io"
+	"io/fs"
 	"os"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	dt "github.com/globalcyberalliance/domain-trust-go/v2"
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newDomainsCMD() *cobra.Command {
@@ -26,42 +29,191 @@ func newDomainsCMD() *cobra.Command {
 	}
 
 	cmd.AddCommand(newDomainsCreateCMD())
+	cmd.AddCommand(newDomainsDeleteCMD())
+	cmd.AddCommand(newDomainsExportCMD())
 	cmd.AddCommand(newDomainsFindCMD())
+	cmd.AddCommand(newDomainsGetCMD())
+	cmd.AddCommand(newDomainsUpdateCMD())
 
 	return cmd
 }
 
 func newDomainsCreateCMD() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "create",
-		Short:   "Create domains",
-		Example: "  client domains create\n  client users create < input.csv",
-		Args:    cobra.ExactArgs(0),
+		Use:   "create",
+		Short: "Create domains",
+		Long: "Create domains read from stdin (or --file). CSV input needs a header row, with columns matched against the\n" +
+			"domain fields (e.g. domain, abuseType, activity, classification, dateIdentified, isBlocked, urls). JSON, NDJSON\n" +
+			"and YAML records use the same field names, STIX bundles are read from their indicators and domain-name objects,\n" +
+			"and txt input is a plain list of domains. Flags such as --classification and --abuseType fill in any empty\n" +
+			"fields, and rows that fail validation are written to a rejects CSV along with the reasons.",
+		Example: "  client domains create < input.csv\n" +
+			"  client domains create --dry-run --file=feed.ndjson\n" +
+			"  client domains create --input-format=stix --file=bundle.json\n" +
+			"  client domains create --input-format=txt --classification=definitely-malicious --abuseType=phishing < domains.txt",
+		Args: cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, _ []string) {
-			// Read CSV from stdin.
-			domains, err := readDomainsCSV(os.Stdin)
+			dryRun, err := cmd.Flags().GetBool("dry-run")
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to parse CSV from stdin")
+				log.Fatal().Err(err).Msg("Failed to get flag 'dry-run'")
 			}
-			if len(domains) == 0 {
-				log.Fatal().Msg("No domain rows found in CSV")
+
+			rejectsFile, err := cmd.Flags().GetString("rejects-file")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'rejects-file'")
+			}
+
+			inputFile, err := cmd.Flags().GetString("file")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'file'")
+			}
+
+			inputFormat, err := cmd.Flags().GetString("input-format")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'input-format'")
+			}
+
+			var defaults model.DomainSubmission
+
+			if err = unmarshalFlags(cmd, &defaults); err != nil {
+				log.Fatal().Err(err).Msg("Failed to unmarshal flags")
+			}
+
+			if value, _ := cmd.Flags().GetString("dateIdentified"); value != "" {
+				if defaults.DateIdentified, err = parseDate(value); err != nil {
+					log.Fatal().Err(err).Msg("Failed to parse flag 'dateIdentified'")
+				}
+			}
+
+			input := os.Stdin
+
+			if inputFile != "" && inputFile != "-" {
+				if input, err = os.Open(inputFile); err != nil {
+					log.Fatal().Err(err).Msg("Failed to open input file")
+				}
+				defer input.Close()
+
+				if inputFormat == "" {
+					inputFormat = inputFormatFromPath(inputFile)
+				}
 			}
 
-			errs, err := apiClient.CreateDomains(cmd.Context(), domains...)
+			if inputFormat == "" {
+				inputFormat = "csv"
+			}
+
+			domains, header, rejected, err := readDomains(input, strings.ToLower(inputFormat), &defaults)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to create domains")
+				log.Fatal().Err(err).Msg("Failed to parse input")
 			}
 
-			if len(errs) > 0 {
-				log.Info().Msg("Domains created with errors")
-				for _, domain := range errs {
-					printToConsole(domain)
+			if len(rejected) > 0 {
+				if rejectsFile == "" {
+					rejectsFile = cast.ToString(time.Now().Unix()) + "-rejected.csv"
+				}
+
+				if err = writeRejectedCSV(rejectsFile, header, rejected); err != nil {
+					log.Fatal().Err(err).Msg("Failed to write rejected rows")
 				}
 
+				log.Warn().Int("rowsRejected", len(rejected)).Msg("Rejected rows written to " + rejectsFile)
+			}
+
+			if len(domains) == 0 {
+				log.Fatal().Msg("No valid domains found in input")
+			}
+
+			if dryRun {
+				log.Info().Int("rowsValid", len(domains)).Int("rowsRejected", len(rejected)).Msg("Dry run, no domains were created")
+				printToConsole(domains)
+
 				return
 			}
 
-			log.Info().Msg("Domains created!")
+			batchSize, err := cmd.Flags().GetInt("batchSize")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'batchSize'")
+			}
+
+			concurrency, err := cmd.Flags().GetInt("concurrency")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'concurrency'")
+			}
+
+			retries, err := cmd.Flags().GetInt("retries")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'retries'")
+			}
+
+			progress := newProgressBar("Submitting domains")
+
+			result, err := apiClient.BulkCreateDomains(cmd.Context(), domains,
+				dt.WithBatchSize(batchSize),
+				dt.WithBatchConcurrency(concurrency),
+				dt.WithBatchRetries(retries),
+				dt.WithBatchProgress(progress.Update),
+			)
+			progress.Finish()
+
+			if len(result.Errors) > 0 {
+				log.Warn().Int("domainErrors", len(result.Errors)).Msg("Some domains were refused")
+				printToConsole(result.Errors)
+			}
+
+			if err != nil {
+				var failed []*model.DomainSubmission
+				for _, batch := range result.Failed {
+					failed = append(failed, batch.Domains...)
+				}
+
+				failedFile := cast.ToString(time.Now().Unix()) + "-failed.csv"
+				if wErr := writeDomainsCSV(failedFile, failed); wErr != nil {
+					log.Error().Err(wErr).Msg("Failed to write unsubmitted domains")
+				} else {
+					log.Warn().Msg("Unsubmitted domains written to " + failedFile + ", which can be passed back to this command")
+				}
+
+				log.Fatal().Err(err).Int("domainsCreated", result.Submitted).Int("domainsFailed", len(failed)).Msg("Failed to create domains")
+			}
+
+			log.Info().Int("domainsCreated", result.Submitted-len(result.Errors)).Msg("Domains created!")
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Validate the input and print the parsed domains without creating them")
+	cmd.Flags().String("file", "", "Read domains from this file instead of stdin")
+	cmd.Flags().String("input-format", "", "The input format (csv|json|ndjson|stix|txt|yaml), guessed from the --file extension by default")
+	cmd.Flags().String("rejects-file", "", "Where to write rejected rows (defaults to <unix time>-rejected.csv)")
+	cmd.Flags().Int("batchSize", dt.DefaultBulkBatchSize, "The number of domains to submit per request")
+	cmd.Flags().Int("concurrency", dt.DefaultBulkConcurrency, "The number of requests to send at once")
+	cmd.Flags().Int("retries", dt.DefaultBulkRetries, "How many times to retry a failed batch")
+
+	// Defaults for fields the input leaves empty.
+	cmd.Flags().String("abuseType", "", "Default abuse type (botnets|malware|pharming|phishing|spam)")
+	cmd.Flags().String("activity", "", "Default activity (active|suspended|non-existent|taken-down|blocked)")
+	cmd.Flags().String("classification", "", "Default classification (definitely-malicious|probably-malicious|possibly-malicious|definitely-clean)")
+	cmd.Flags().String("comments", "", "Default comments")
+	cmd.Flags().String("dateIdentified", "", "Default date the domain was identified (e.g. 2006-01-02 or RFC 3339)")
+	cmd.Flags().String("reportType", "", "Default report type (brand-spoof|fraud)")
+	cmd.Flags().String("source", "", "Default source (self-reported|external-reported)")
+	cmd.Flags().String("sourceName", "", "Default source name")
+
+	return cmd
+}
+
+func newDomainsDeleteCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete",
+		Short:   "Delete domain",
+		Example: "  client domains delete :id\n  client domains delete 019a0dd4-11a5-7477-91a8-538b1bc334e4",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := apiClient.DeleteDomain(cmd.Context(), args[0]); err != nil {
+				log.Fatal().Err(err).Msg("Failed to delete domain")
+			}
+
+			printToConsole("Domain " + args[0] + " successfully deleted!")
 		},
 	}
 
@@ -84,11 +236,9 @@ func newDomainsFindCMD() *cobra.Command {
 				log.Fatal().Err(err).Msg("Failed to get flag 'all'")
 			}
 
-			var domains []*model.Domain
-
 			// If findAll is false, do a simple lookup and return the results.
 			if !findAll {
-				domains, err = apiClient.FindDomains(cmd.Context(), &filter)
+				domains, err := apiClient.FindDomains(cmd.Context(), &filter)
 				if err != nil {
 					log.Fatal().Err(err).Msg("Failed to find domains")
 				}
@@ -102,54 +252,30 @@ func newDomainsFindCMD() *cobra.Command {
 				return
 			}
 
-			go func() {
-				ticker := time.NewTicker(10 * time.Second)
-				defer ticker.Stop()
-
-				for {
-					select {
-					case <-cmd.Context().Done():
-						return
-					case <-ticker.C:
-						log.Info().
-							Int("domainsFound", len(domains)).
-							Msg("Tracking domains")
-					}
-				}
-			}()
-
-			log.Info().Msg("Starting domain retrieval...")
-
 			// Paginate over results.
 			filter.MetadataFilter.Limit = model.MaxMetadataLimit
 
-			domainIterator, err := apiClient.FindDomainsPaged(cmd.Context(), &filter)
+			checkpointFile, err := cmd.Flags().GetString("checkpoint-file")
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to find all domains")
-			}
-
-			domains = make([]*model.Domain, 0, model.MaxMetadataLimit)
-
-			for domainIterator.Next() {
-				domains = append(domains, domainIterator.Value())
+				log.Fatal().Err(err).Msg("Failed to get flag 'checkpoint-file'")
 			}
 
-			if domainIterator.Err() != nil {
-				log.Fatal().Err(domainIterator.Err()).Msg("Failed to page domains")
+			resume, err := cmd.Flags().GetBool("resume")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'resume'")
 			}
 
-			if len(domains) == 0 {
-				log.Warn().Msg("No domains found")
-				return
+			if resume && checkpointFile == "" {
+				log.Fatal().Msg("--resume requires --checkpoint-file")
 			}
 
-			log.Info().Int("domainsFound", len(domains)).Msg("Successfully retrieved domains")
-
-			printToConsole(domains)
+			findAllDomains(cmd.Context(), &filter, checkpointFile, resume)
 		},
 	}
 
 	cmd.Flags().Bool("all", false, "Automatically paginate through the results")
+	cmd.Flags().String("checkpoint-file", "", "With --all, save progress to this file after each page")
+	cmd.Flags().Bool("resume", false, "With --all, continue from the page saved in --checkpoint-file")
 	cmd.Flags().String("organizationID", "", "A unique identifier for the organization")
 
 	// Domain details.
@@ -177,101 +303,262 @@ func newDomainsFindCMD() *cobra.Command {
 	return cmd
 }
 
-// readDomainsCSV parses a CSV with a header row into domainDTOs.
-// Recognized headers (case-insensitive):
-// abuseType, activity, classification, comments, dateIdentified, domain,
-// reportType, rootDomain, source, sourceName, urls.
-func readDomainsCSV(r io.Reader) ([]*model.DomainSubmission, error) {
-	cr := csv.NewReader(bufio.NewReader(r))
-	cr.TrimLeadingSpace = true
-	cr.FieldsPerRecord = -1 // Allow variable columns.
+func newDomainsGetCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Get domain",
+		Example: "  client domains get :id\n  client domains get 019a0dd4-11a5-7477-91a8-538b1bc334e4",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			domain, err := apiClient.FindDomainByID(cmd.Context(), args[0])
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get domain " + args[0])
+			}
 
-	header, err := cr.Read()
-	if err != nil {
-		return nil, fmt.Errorf("read csv header: %w", err)
+			printToConsole(domain)
+		},
 	}
 
-	// Map header to index.
-	idx := make(map[string]int, len(header))
-	for i, h := range header {
-		idx[strings.ToLower(strings.TrimSpace(h))] = i
-	}
+	return cmd
+}
+
+func newDomainsUpdateCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "update",
+		Short:   "Update domain",
+		Example: "  client domains update :id\n  client domains update 019a0dd4-11a5-7477-91a8-538b1bc334e4 --providerRating=low-confidence",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			var update model.DomainUpdate
+
+			cmd.Flags().Visit(func(flag *pflag.Flag) {
+				val := flag.Value.String()
+				switch flag.Name {
+				case "organizationID":
+					update.OrganizationID = val
+				case "providerName":
+					update.ProviderName = &val
+				case "providerRating":
+					update.ProviderRating = &val
+				case "providerRole":
+					update.ProviderRole = &val
+				}
+			})
 
-	var out []*model.DomainSubmission
-	for {
-		rec, rErr := cr.Read()
-		if rErr != nil {
-			if errors.Is(rErr, io.EOF) {
-				break
+			domain, err := apiClient.UpdateDomain(cmd.Context(), args[0], &update)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to update domain")
 			}
-			return nil, fmt.Errorf("read csv record: %w", rErr)
+
+			printToConsole(domain)
+		},
+	}
+
+	cmd.Flags().String("organizationID", "", "Update the organization the submission belongs to")
+	cmd.Flags().String("providerName", "", "Update the name of the provider organization")
+	cmd.Flags().String("providerRating", "", "Update the rating of the provider organization (trial|predictive|low-confidence|med-confidence|high-confidence)")
+	cmd.Flags().String("providerRole", "", "Update the role/type of the provider organization (registrar|registry|reseller|other|icann)")
+
+	return cmd
+}
+
+// findAllDomains pages through all domains matching the filter, streaming each record to the output as it arrives.
+// If checkpointFile is set, the next page token is recorded after every page, and resume picks up from that saved
+// checkpoint (appending to the same output file when writing to disk).
+func findAllDomains(ctx context.Context, filter *model.DomainFilter, checkpointFile string, resume bool) {
+	domainIterator, err := apiClient.FindDomainsPaged(ctx, filter)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to find all domains")
+	}
+
+	progress := &checkpoint{}
+
+	if resume {
+		saved, cErr := loadCheckpoint(checkpointFile)
+		if cErr != nil {
+			log.Fatal().Err(cErr).Msg("Failed to load checkpoint")
 		}
 
-		// Helper to safely fetch a field by name.
-		get := func(name string) string {
-			if j, ok := idx[name]; ok && j < len(rec) {
-				return strings.TrimSpace(rec[j])
+		if saved == nil {
+			log.Warn().Str("checkpointFile", checkpointFile).Msg("No checkpoint found, starting from the beginning")
+		} else {
+			matches, mErr := saved.matches(filter)
+			if mErr != nil {
+				log.Fatal().Err(mErr).Msg("Failed to compare checkpoint filter")
+			}
+
+			if !matches {
+				log.Fatal().Str("checkpointFile", checkpointFile).Msg("Checkpoint was created with different filter flags")
+			}
+
+			// Appending records in another format (or to another destination) would leave the output unusable.
+			if saved.Format != strings.ToLower(format) {
+				log.Fatal().Str("checkpointFile", checkpointFile).Str("checkpointFormat", saved.Format).
+					Msg("Checkpoint was created with a different output format")
+			}
+
+			if writeToFile != (saved.Output != "") {
+				log.Fatal().Str("checkpointFile", checkpointFile).Str("checkpointOutput", saved.Output).
+					Msg("Checkpoint was created with a different output destination (-w)")
+			}
+
+			// The last page was written, but the run stopped before it could clean up.
+			if saved.PageToken == "" && saved.Items > 0 {
+				log.Info().Uint64("domainsFound", saved.Items).Msg("Checkpoint shows the retrieval already completed")
+
+				if err = os.Remove(checkpointFile); err != nil {
+					log.Warn().Err(err).Msg("Failed to remove checkpoint file")
+				}
+
+				return
 			}
-			return ""
+
+			progress = saved
+			domainIterator.ResumeFrom(saved.PageToken)
+
+			log.Info().Uint64("domainsFound", saved.Items).Msg("Resuming domain retrieval from checkpoint")
+		}
+	}
+
+	progress.Format = strings.ToLower(format)
+
+	output := os.Stdout
+	header := true
+
+	if writeToFile {
+		if progress.Output == "" {
+			progress.Output = outputFilename()
+		}
+
+		output, err = os.OpenFile(progress.Output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to open output file")
 		}
+		defer output.Close()
 
-		// Split urls by comma/semicolon/whitespace.
-		urlsRaw := get("urls")
-		var urls []string
-		if urlsRaw != "" {
-			for _, u := range splitMulti(urlsRaw, []rune{',', ';', ' ', '\t', '\n'}) {
-				if u != "" {
-					urls = append(urls, u)
+		info, sErr := output.Stat()
+		if sErr != nil {
+			log.Fatal().Err(sErr).Msg("Failed to stat output file")
+		}
+
+		size := info.Size()
+
+		if progress.PageToken != "" {
+			// Drop whatever was written after the checkpoint, since those records will be fetched again.
+			if size < progress.Offset {
+				log.Fatal().Str("output", progress.Output).Int64("size", size).Int64("checkpointOffset", progress.Offset).
+					Msg("Output file is shorter than when the checkpoint was saved")
+			}
+
+			if size > progress.Offset {
+				if err = output.Truncate(progress.Offset); err != nil {
+					log.Fatal().Err(err).Msg("Failed to truncate output file to the checkpoint")
 				}
+
+				log.Info().Int64("bytes", size-progress.Offset).Msg("Discarded output written after the checkpoint")
+
+				size = progress.Offset
+			}
+		}
+
+		// Don't repeat the CSV header when appending to a resumed export.
+		header = size == 0
+	}
+
+	writer := newRecordWriter(output, outputColumns(reflect.TypeFor[model.Domain]()), header)
+
+	var domainsFound atomic.Uint64
+	domainsFound.Store(progress.Items)
+
+	trackCtx, stopTracking := context.WithCancel(ctx)
+	defer stopTracking()
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-trackCtx.Done():
+				return
+			case <-ticker.C:
+				log.Info().
+					Uint64("domainsFound", domainsFound.Load()).
+					Msg("Tracking domains")
 			}
 		}
+	}()
+
+	log.Info().Msg("Starting domain retrieval...")
 
-		d := model.DomainSubmission{
-			AbuseType:      get("abusetype"),
-			Activity:       get("activity"),
-			Classification: get("classification"),
-			Comments:       get("comments"),
-			Domain:         get("domain"),
-			ReportType:     get("reporttype"),
-			Source:         get("source"),
-			SourceName:     get("sourcename"),
-			URLs:           urls,
+	for domainIterator.Next() {
+		if err = writer.Write(domainIterator.Value()); err != nil {
+			log.Fatal().Err(err).Msg("Failed to write output")
 		}
 
-		// Require a domain value.
-		if d.Domain == "" {
-			// Skip empty rows silently.
+		domainsFound.Add(1)
+
+		if checkpointFile == "" || !domainIterator.PageDone() {
 			continue
 		}
 
-		out = append(out, &d)
-	}
+		// Flush the page before recording it, so a crash can only ever repeat a page rather than skip one.
+		if err = writer.Flush(); err != nil {
+			log.Fatal().Err(err).Msg("Failed to write output")
+		}
 
-	return out, nil
-}
+		progress.Items = domainsFound.Load()
+		progress.PageToken = domainIterator.NextPageToken()
+
+		if writeToFile {
+			info, sErr := output.Stat()
+			if sErr != nil {
+				log.Fatal().Err(sErr).Msg("Failed to stat output file")
+			}
+
+			progress.Offset = info.Size()
+		}
 
-// splitMulti splits s by any of the given runes.
-func splitMulti(s string, seps []rune) []string {
-	if s == "" {
-		return nil
+		if err = progress.save(checkpointFile, filter); err != nil {
+			log.Fatal().Err(err).Msg("Failed to save checkpoint")
+		}
 	}
 
-	fields := strings.FieldsFunc(s, func(r rune) bool {
-		for _, sep := range seps {
-			if r == sep {
-				return true
-			}
+	if err = writer.Flush(); err != nil {
+		log.Fatal().Err(err).Msg("Failed to write output")
+	}
+
+	// Leave the output open (e.g. without a JSON array's closing bracket) when paging fails, so a resumed run can
+	// continue it.
+	if domainIterator.Err() != nil {
+		event := log.Fatal().Err(domainIterator.Err())
+		if checkpointFile != "" {
+			event = event.Str("checkpointFile", checkpointFile)
 		}
-		return false
-	})
-
-	trimmed := make([]string, 0, len(fields))
-	for _, v := range fields {
-		v = strings.TrimSpace(v)
-		if v != "" {
-			trimmed = append(trimmed, v)
+
+		event.Msg("Failed to page domains")
+	}
+
+	if err = writer.Close(); err != nil {
+		log.Fatal().Err(err).Msg("Failed to write output")
+	}
+
+	if checkpointFile != "" {
+		if err = os.Remove(checkpointFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Warn().Err(err).Msg("Failed to remove checkpoint file")
 		}
 	}
 
-	return trimmed
+	if domainsFound.Load() == 0 {
+		log.Warn().Msg("No domains found")
+		return
+	}
+
+	log.Info().Uint64("domainsFound", domainsFound.Load()).Msg("Successfully retrieved domains")
+
+	if writeToFile {
+		log.Info().Msg("Output written to " + progress.Output)
+	}
 }
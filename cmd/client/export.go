@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/convert"
+	"github.com/globalcyberalliance/domain-trust-go/v2/domainutil"
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exportFormatAdblock = "adblock"
+	exportFormatDnsmasq = "dnsmasq"
+	exportFormatHosts   = "hosts"
+	exportFormatMISP    = "misp"
+	exportFormatRPZ     = "rpz"
+	exportFormatSTIX    = "stix"
+	exportFormatUnbound = "unbound"
+)
+
+type (
+	// blocklistFormat describes how to render a resolver blocklist.
+	blocklistFormat struct {
+		extension string
+		header    func(w io.Writer, opts *exportOptions) error
+		entry     func(w io.Writer, domain string, opts *exportOptions) error
+	}
+
+	// exportFile is where an export is written. With -w, that's a temporary file that's only renamed into place once
+	// the export is complete, so a failed export never leaves a truncated blocklist or an unfinished document behind.
+	exportFile struct {
+		*os.File
+		filename string
+	}
+
+	// exportOptions holds the settings shared by the export formats.
+	exportOptions struct {
+		filter     *model.DomainFilter
+		hostmaster string
+		info       string
+		nameserver string
+		sinkhole   string
+		zone       string
+		expire     uint32
+		minimum    uint32
+		refresh    uint32
+		retry      uint32
+		serial     uint32
+		ttl        uint32
+		wildcard   bool
+	}
+
+	// intelFormat describes how to render a threat intelligence sharing document.
+	intelFormat struct {
+		extension string
+		writer    func(w io.Writer, opts *exportOptions) (intelWriter, error)
+	}
+
+	// intelWriter streams domains into a threat intelligence sharing document.
+	intelWriter interface {
+		Write(domain *model.Domain) error
+		Close() error
+	}
+)
+
+var blocklistFormats = map[string]blocklistFormat{
+	exportFormatAdblock: {
+		extension: "txt",
+		header: func(w io.Writer, _ *exportOptions) error {
+			_, err := fmt.Fprintf(w, "[Adblock Plus 2.0]\n! Title: Domain Trust blocklist\n! Last modified: %s\n", time.Now().UTC().Format(time.RFC3339))
+			return err
+		},
+		entry: func(w io.Writer, domain string, _ *exportOptions) error {
+			_, err := fmt.Fprintf(w, "||%s^\n", domain)
+			return err
+		},
+	},
+	exportFormatDnsmasq: {
+		extension: "conf",
+		header:    commentHeader("#"),
+		entry: func(w io.Writer, domain string, opts *exportOptions) error {
+			_, err := fmt.Fprintf(w, "address=/%s/%s\n", domain, opts.sinkhole)
+			return err
+		},
+	},
+	exportFormatHosts: {
+		extension: "hosts",
+		header:    commentHeader("#"),
+		entry: func(w io.Writer, domain string, opts *exportOptions) error {
+			_, err := fmt.Fprintf(w, "%s %s\n", opts.sinkhole, domain)
+			return err
+		},
+	},
+	exportFormatRPZ: {
+		extension: "zone",
+		header: func(w io.Writer, opts *exportOptions) error {
+			if err := commentHeader(";")(w, opts); err != nil {
+				return err
+			}
+
+			_, err := fmt.Fprintf(w, "$TTL %d\n$ORIGIN %s\n@ IN SOA %s %s ( %d %d %d %d %d )\n@ IN NS %s\n",
+				opts.ttl, opts.zone, opts.nameserver, opts.hostmaster, opts.serial, opts.refresh, opts.retry, opts.expire, opts.minimum, opts.nameserver)
+
+			return err
+		},
+		entry: func(w io.Writer, domain string, opts *exportOptions) error {
+			if _, err := fmt.Fprintf(w, "%s CNAME .\n", domain); err != nil {
+				return err
+			}
+
+			if opts.wildcard {
+				if _, err := fmt.Fprintf(w, "*.%s CNAME .\n", domain); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	exportFormatUnbound: {
+		extension: "conf",
+		header: func(w io.Writer, opts *exportOptions) error {
+			if err := commentHeader("#")(w, opts); err != nil {
+				return err
+			}
+
+			_, err := io.WriteString(w, "server:\n")
+
+			return err
+		},
+		entry: func(w io.Writer, domain string, _ *exportOptions) error {
+			_, err := fmt.Fprintf(w, "  local-zone: \"%s.\" always_nxdomain\n", domain)
+			return err
+		},
+	},
+}
+
+var intelFormats = map[string]intelFormat{
+	exportFormatMISP: {
+		extension: "json",
+		writer: func(w io.Writer, opts *exportOptions) (intelWriter, error) {
+			return convert.NewMISPEventWriter(w, opts.info)
+		},
+	},
+	exportFormatSTIX: {
+		extension: "json",
+		writer: func(w io.Writer, _ *exportOptions) (intelWriter, error) {
+			return convert.NewSTIXBundleWriter(w)
+		},
+	},
+}
+
+func newDomainsExportCMD() *cobra.Command {
+	var opts exportOptions
+	var exportFormat string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export domains as a ready-to-load resolver blocklist, STIX 2.1 bundle or MISP event",
+		Example: "  client domains export --format=rpz --classification=definitely-malicious -w\n" +
+			"  client domains export --format=hosts --providerRatingAbove=low-confidence --activity=active > blocklist.hosts\n" +
+			"  client domains export --format=stix --classification=definitely-malicious > bundle.json",
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			var filter model.DomainFilter
+
+			if err := unmarshalFlags(cmd, &filter); err != nil {
+				log.Fatal().Err(err).Msg("Failed to unmarshal flags")
+			}
+
+			filter.MetadataFilter.Limit = model.MaxMetadataLimit
+			opts.filter = &filter
+			opts.hostmaster = fqdn(opts.hostmaster)
+			opts.nameserver = fqdn(opts.nameserver)
+			opts.zone = fqdn(opts.zone)
+
+			if opts.serial == 0 {
+				opts.serial = uint32(time.Now().Unix()) //nolint:gosec // Unix time fits in a uint32 until 2106.
+			}
+
+			exportFormat = strings.ToLower(exportFormat)
+
+			if intel, ok := intelFormats[exportFormat]; ok {
+				exportIntel(cmd.Context(), intel, &opts)
+				return
+			}
+
+			blocklist, ok := blocklistFormats[exportFormat]
+			if !ok {
+				formats := append(slices.Collect(maps.Keys(blocklistFormats)), slices.Collect(maps.Keys(intelFormats))...)
+				slices.Sort(formats)
+
+				log.Fatal().Msg("Unknown export format " + exportFormat + " (" + strings.Join(formats, "|") + ")")
+			}
+
+			exportBlocklist(cmd.Context(), blocklist, &opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&exportFormat, "format", "f", "", "The export format (adblock|dnsmasq|hosts|misp|rpz|stix|unbound)")
+	_ = markFlagsRequired(cmd, "format")
+
+	// Filters.
+	cmd.Flags().String("activity", "", "Only export domains with this activity (active|suspended|non-existent|taken-down|blocked)")
+	cmd.Flags().String("classification", "", "Only export domains with this classification (definitely-malicious|probably-malicious|possibly-malicious|definitely-clean)")
+	cmd.Flags().String("providerRatingAbove", "", "Only export domains from providers rated above this (trial|predictive|low-confidence|med-confidence|high-confidence)")
+
+	// Output settings.
+	cmd.Flags().StringVar(&opts.sinkhole, "sinkhole", "0.0.0.0", "The address blocked domains resolve to (dnsmasq, hosts)")
+	cmd.Flags().BoolVar(&opts.wildcard, "wildcard", true, "Also block subdomains of each domain (rpz)")
+	cmd.Flags().StringVar(&opts.info, "info", "Domain Trust export", "The event description (misp)")
+
+	// RPZ zone settings.
+	cmd.Flags().StringVar(&opts.zone, "zone", "rpz.domain-trust", "The RPZ zone origin")
+	cmd.Flags().StringVar(&opts.nameserver, "nameserver", "localhost", "The RPZ SOA primary nameserver (MNAME)")
+	cmd.Flags().StringVar(&opts.hostmaster, "hostmaster", "hostmaster.localhost", "The RPZ SOA responsible mailbox (RNAME)")
+	cmd.Flags().Uint32Var(&opts.serial, "serial", 0, "The RPZ SOA serial (defaults to the current Unix time)")
+	cmd.Flags().Uint32Var(&opts.ttl, "ttl", 300, "The RPZ default TTL in seconds")             //nolint:mnd // Default TTL.
+	cmd.Flags().Uint32Var(&opts.refresh, "refresh", 3600, "The RPZ SOA refresh in seconds")    //nolint:mnd // Default refresh.
+	cmd.Flags().Uint32Var(&opts.retry, "retry", 600, "The RPZ SOA retry in seconds")           //nolint:mnd // Default retry.
+	cmd.Flags().Uint32Var(&opts.expire, "expire", 86400, "The RPZ SOA expire in seconds")      //nolint:mnd // Default expire.
+	cmd.Flags().Uint32Var(&opts.minimum, "minimum", 300, "The RPZ SOA minimum TTL in seconds") //nolint:mnd // Default minimum.
+
+	return cmd
+}
+
+// commentHeader returns a header func that writes a short provenance comment using the given comment prefix.
+func commentHeader(prefix string) func(w io.Writer, opts *exportOptions) error {
+	return func(w io.Writer, opts *exportOptions) error {
+		_, err := fmt.Fprintf(w, "%s Domain Trust blocklist, generated %s\n%s Filter: %s\n",
+			prefix, time.Now().UTC().Format(time.RFC3339), prefix, describeFilter(opts.filter))
+
+		return err
+	}
+}
+
+// describeFilter summarizes the filter values that were set, for use in blocklist headers.
+func describeFilter(filter *model.DomainFilter) string {
+	var parts []string
+
+	for _, kv := range [][2]string{
+		{"activity", filter.Activity},
+		{"classification", filter.Classification},
+		{"providerRatingAbove", filter.ProviderRatingAbove},
+	} {
+		if kv[1] != "" {
+			parts = append(parts, kv[0]+"="+kv[1])
+		}
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// exportBlocklist streams every matching, non-whitelisted domain into the given blocklist format.
+func exportBlocklist(ctx context.Context, blocklist blocklistFormat, opts *exportOptions) {
+	output := exportOutput(blocklist.extension)
+	w := bufio.NewWriter(output)
+
+	if err := blocklist.header(w, opts); err != nil {
+		output.fail(err, "Failed to write blocklist header")
+	}
+
+	domainIterator, err := apiClient.FindDomainsPaged(ctx, opts.filter)
+	if err != nil {
+		output.fail(err, "Failed to find domains")
+	}
+
+	var exported, skipped uint64
+
+	log.Info().Msg("Starting domain export...")
+
+	for domainIterator.Next() {
+		domain := domainIterator.Value()
+
+		// Resolvers match blocklist entries against the ASCII (punycode) form of a name, so internationalized names are
+		// converted, and anything that isn't a valid domain is left out.
+		name, nErr := domainutil.Normalize(domain.Domain)
+		if len(domain.Whitelist) > 0 || nErr != nil {
+			skipped++
+			continue
+		}
+
+		if err = blocklist.entry(w, name, opts); err != nil {
+			output.fail(err, "Failed to write blocklist entry")
+		}
+
+		exported++
+	}
+
+	if domainIterator.Err() != nil {
+		output.fail(domainIterator.Err(), "Failed to page domains")
+	}
+
+	if err = w.Flush(); err != nil {
+		output.fail(err, "Failed to write blocklist")
+	}
+
+	if err = output.commit(); err != nil {
+		output.fail(err, "Failed to write blocklist")
+	}
+
+	log.Info().Uint64("domainsExported", exported).Uint64("domainsSkipped", skipped).Msg("Successfully exported domains")
+
+	if output.filename != "" {
+		log.Info().Msg("Output written to " + output.filename)
+	}
+}
+
+// exportIntel streams every matching domain into the given threat intelligence sharing format.
+func exportIntel(ctx context.Context, intel intelFormat, opts *exportOptions) {
+	output := exportOutput(intel.extension)
+	buf := bufio.NewWriter(output)
+
+	w, err := intel.writer(buf, opts)
+	if err != nil {
+		output.fail(err, "Failed to write export header")
+	}
+
+	domainIterator, err := apiClient.FindDomainsPaged(ctx, opts.filter)
+	if err != nil {
+		output.fail(err, "Failed to find domains")
+	}
+
+	var exported uint64
+
+	log.Info().Msg("Starting domain export...")
+
+	for domainIterator.Next() {
+		if err = w.Write(domainIterator.Value()); err != nil {
+			output.fail(err, "Failed to write domain")
+		}
+
+		exported++
+	}
+
+	if domainIterator.Err() != nil {
+		output.fail(domainIterator.Err(), "Failed to page domains")
+	}
+
+	if err = w.Close(); err != nil {
+		output.fail(err, "Failed to finish export")
+	}
+
+	if err = buf.Flush(); err != nil {
+		output.fail(err, "Failed to write export")
+	}
+
+	if err = output.commit(); err != nil {
+		output.fail(err, "Failed to write export")
+	}
+
+	log.Info().Uint64("domainsExported", exported).Msg("Successfully exported domains")
+
+	if output.filename != "" {
+		log.Info().Msg("Output written to " + output.filename)
+	}
+}
+
+// exportOutput returns where an export should be written: stdout, or (when -w is set) a temporary file that commit
+// renames to a new timestamped file with the given extension.
+func exportOutput(extension string) *exportFile {
+	if !writeToFile {
+		return &exportFile{File: os.Stdout}
+	}
+
+	filename := cast.ToString(time.Now().Unix()) + "." + extension
+
+	// Create the temporary file next to the final one, so it can be renamed into place.
+	file, err := os.CreateTemp(".", "."+filename+".*.tmp")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open output file")
+	}
+
+	return &exportFile{File: file, filename: filename}
+}
+
+// commit closes the output file and moves it into place. It does nothing when writing to stdout.
+func (f *exportFile) commit() error {
+	if f.filename == "" {
+		return nil
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close output file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), f.filename); err != nil {
+		return fmt.Errorf("move output file into place: %w", err)
+	}
+
+	return nil
+}
+
+// fail removes the unfinished output file (if there is one), then exits with err.
+func (f *exportFile) fail(err error, msg string) {
+	if f.filename != "" {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	log.Fatal().Err(err).Msg(msg)
+}
+
+// fqdn returns name with a single trailing dot.
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
@@ -0,0 +1,859 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/convert"
+	"github.com/globalcyberalliance/domain-trust-go/v2/domainutil"
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"gopkg.in/yaml.v3"
+)
+
+// maxImportLineSize is the longest line accepted in line-based input formats.
+const maxImportLineSize = 1024 * 1024
+
+// importRow is a single parsed input record, along with the reasons it was rejected (if any).
+type importRow struct {
+	domain  *model.DomainSubmission
+	record  []string
+	reasons []string
+	line    int
+}
+
+var (
+	// domainReaders parse each supported input format into rows. Each also returns the header describing its rows'
+	// records, for the rejects file.
+	domainReaders = map[string]func(r io.Reader) ([]*importRow, []string, error){
+		"csv":    readDomainsCSV,
+		"json":   readDomainsJSON,
+		"ndjson": readDomainsNDJSON,
+		"stix":   readDomainsSTIX,
+		"txt":    readDomainsTXT,
+		"yaml":   readDomainsYAML,
+	}
+
+	// dateLayouts are the date formats accepted in imported files, tried in order. Unix timestamps (in seconds) are also
+	// accepted.
+	dateLayouts = []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		time.DateTime,
+		"2006-01-02 15:04",
+		time.DateOnly,
+		"2006/01/02",
+		time.RFC1123Z,
+		time.RFC1123,
+	}
+
+	domainAbuseTypes = []string{
+		model.DomainAbuseTypeBotnets, model.DomainAbuseTypeMalware, model.DomainAbuseTypePharming,
+		model.DomainAbuseTypePhishing, model.DomainAbuseTypeSpam,
+	}
+
+	domainActivities = []string{
+		model.DomainActivityActive, model.DomainActivitySuspended, model.DomainActivityNonExistent,
+		model.DomainActivityTakenDown, model.DomainActivityBlocked,
+	}
+
+	domainClassifications = []string{
+		model.DomainClassificationDefinitelyMalicious, model.DomainClassificationProbablyMalicious,
+		model.DomainClassificationPossiblyMalicious, model.DomainClassificationDefinitelyClean,
+	}
+
+	domainReportTypes = []string{model.DomainReportTypeBrandSpoof, model.DomainReportTypeFraud}
+
+	domainSources = []string{model.DomainSourceExternal, model.DomainSourceInternal}
+
+	organizationRatings = []string{
+		model.OrganizationRatingTrial, model.OrganizationRatingPredictive, model.OrganizationRatingLowConfidence,
+		model.OrganizationRatingMedConfidence, model.OrganizationRatingHighConfidence,
+	}
+
+	organizationRoles = []string{
+		model.OrganizationRoleICANN, model.OrganizationRoleOther, model.OrganizationRoleRegistrar,
+		model.OrganizationRoleRegistry, model.OrganizationRoleReseller,
+	}
+
+	userRoles = []string{model.UserRoleAdmin, model.UserRoleMember, model.UserRoleTrial}
+
+	// domainCSVFields maps each recognized (lower-cased) CSV header to a setter for the matching DomainSubmission field.
+	domainCSVFields = map[string]func(d *model.DomainSubmission, value string) error{
+		"created": func(d *model.DomainSubmission, v string) (err error) {
+			d.Created, err = parseDate(v)
+			return err
+		},
+		"id":         func(d *model.DomainSubmission, v string) error { d.ID = v; return nil },
+		"providerid": func(d *model.DomainSubmission, v string) error { d.OrganizationID = v; return nil },
+		"domain":     func(d *model.DomainSubmission, v string) error { d.Domain = v; return nil },
+		"sld":        func(d *model.DomainSubmission, v string) error { d.SLD = v; return nil },
+		"tld":        func(d *model.DomainSubmission, v string) error { d.TLD = v; return nil },
+		"rootdomain": func(d *model.DomainSubmission, v string) error { d.RootDomain = v; return nil },
+		"subdomain":  func(d *model.DomainSubmission, v string) error { d.Subdomain = v; return nil },
+		"registrationdate": func(d *model.DomainSubmission, v string) (err error) {
+			d.RegistrationDate, err = parseDate(v)
+			return err
+		},
+		"providername": func(d *model.DomainSubmission, v string) error { d.ProviderName = v; return nil },
+		"providerrating": func(d *model.DomainSubmission, v string) (err error) {
+			d.ProviderRating, err = parseEnum(v, organizationRatings)
+			return err
+		},
+		"providerrole": func(d *model.DomainSubmission, v string) (err error) {
+			d.ProviderRole, err = parseEnum(v, organizationRoles)
+			return err
+		},
+		"abusetype": func(d *model.DomainSubmission, v string) (err error) {
+			d.AbuseType, err = parseEnum(v, domainAbuseTypes)
+			return err
+		},
+		"activity": func(d *model.DomainSubmission, v string) (err error) {
+			d.Activity, err = parseEnum(v, domainActivities)
+			return err
+		},
+		"classification": func(d *model.DomainSubmission, v string) (err error) {
+			d.Classification, err = parseEnum(v, domainClassifications)
+			return err
+		},
+		"comments": func(d *model.DomainSubmission, v string) error { d.Comments = v; return nil },
+		"dateidentified": func(d *model.DomainSubmission, v string) (err error) {
+			d.DateIdentified, err = parseDate(v)
+			return err
+		},
+		"isblocked": func(d *model.DomainSubmission, v string) (err error) {
+			d.IsBlocked, err = parseBool(v)
+			return err
+		},
+		"reporttype": func(d *model.DomainSubmission, v string) (err error) {
+			d.ReportType, err = parseEnum(v, domainReportTypes)
+			return err
+		},
+		"source": func(d *model.DomainSubmission, v string) (err error) {
+			d.Source, err = parseEnum(v, domainSources)
+			return err
+		},
+		"sourcename": func(d *model.DomainSubmission, v string) error { d.SourceName = v; return nil },
+		"urls": func(d *model.DomainSubmission, v string) error {
+			d.URLs = splitMulti(v, []rune{',', ';', ' ', '\t', '\n'})
+			return nil
+		},
+	}
+)
+
+// readDomains parses r using the reader for the given input format, fills any empty fields from defaults, and then
+// validates every row. It returns the valid submissions, the header describing the raw records, and the rejected rows.
+func readDomains(r io.Reader, inputFormat string, defaults *model.DomainSubmission) ([]*model.DomainSubmission, []string, []*importRow, error) {
+	reader, ok := domainReaders[inputFormat]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unknown input format %s (%s)", inputFormat, strings.Join(slices.Sorted(maps.Keys(domainReaders)), "|"))
+	}
+
+	rows, header, err := reader(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		domains  []*model.DomainSubmission
+		rejected []*importRow
+	)
+
+	for _, row := range rows {
+		applyDomainDefaults(row.domain, defaults)
+
+		row.reasons = append(row.reasons, validateDomainSubmission(row.domain)...)
+		if len(row.reasons) > 0 {
+			rejected = append(rejected, row)
+			continue
+		}
+
+		domains = append(domains, row.domain)
+	}
+
+	return domains, header, rejected, nil
+}
+
+// readDomainsCSV parses a CSV with a header row into rows. Headers are matched case-insensitively against the
+// DomainSubmission JSON field names (e.g. domain, abuseType, dateIdentified, isBlocked, urls); unknown headers are
+// ignored with a warning. Enum values must hold one of the model values, dates may use any of dateLayouts, and
+// isBlocked must be a boolean.
+func readDomainsCSV(r io.Reader) ([]*importRow, []string, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1 // Allow variable columns.
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	names := make([]string, len(header))
+	for i, h := range header {
+		names[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+
+		if _, ok := domainCSVFields[names[i]]; !ok {
+			log.Warn().Str("column", h).Msg("Ignoring unknown CSV column")
+		}
+	}
+
+	if !slices.Contains(names, "domain") {
+		return nil, nil, errors.New("csv header has no domain column")
+	}
+
+	var rows []*importRow
+
+	for {
+		rec, rErr := cr.Read()
+		if rErr != nil {
+			if errors.Is(rErr, io.EOF) {
+				break
+			}
+
+			return nil, nil, fmt.Errorf("read csv record: %w", rErr)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		// Skip blank lines.
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+
+		row := &importRow{domain: &model.DomainSubmission{}, line: line, record: rec}
+
+		for i, name := range names {
+			set, ok := domainCSVFields[name]
+			if !ok || i >= len(rec) {
+				continue
+			}
+
+			value := strings.TrimSpace(rec[i])
+			if value == "" {
+				continue
+			}
+
+			if sErr := set(row.domain, value); sErr != nil {
+				row.reasons = append(row.reasons, header[i]+": "+sErr.Error())
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, header, nil
+}
+
+// readDomainsJSON parses a JSON array of domains, an object with a "domains" array (as returned by the API), or a
+// single domain object.
+func readDomainsJSON(r io.Reader) ([]*importRow, []string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read json: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+
+	var records []json.RawMessage
+
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		err = json.Unmarshal(data, &records)
+	case bytes.HasPrefix(data, []byte("{")):
+		var wrapper struct {
+			Domains []json.RawMessage `json:"domains"`
+		}
+
+		if err = json.Unmarshal(data, &wrapper); err == nil {
+			records = wrapper.Domains
+			if records == nil {
+				records = []json.RawMessage{data}
+			}
+		}
+	case len(data) > 0:
+		err = errors.New("expected a json array or object")
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse json: %w", err)
+	}
+
+	rows := make([]*importRow, 0, len(records))
+	for i, record := range records {
+		rows = append(rows, jsonRow(record, i+1))
+	}
+
+	return rows, []string{"record"}, nil
+}
+
+// readDomainsNDJSON parses one JSON domain object per line.
+func readDomainsNDJSON(r io.Reader) ([]*importRow, []string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	var rows []*importRow
+
+	for line := 1; scanner.Scan(); line++ {
+		record := bytes.TrimSpace(scanner.Bytes())
+		if len(record) == 0 {
+			continue
+		}
+
+		rows = append(rows, jsonRow(bytes.Clone(record), line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read ndjson: %w", err)
+	}
+
+	return rows, []string{"record"}, nil
+}
+
+// readDomainsSTIX parses a STIX 2.1 bundle, importing the domains its indicators and domain-name objects refer to.
+func readDomainsSTIX(r io.Reader) ([]*importRow, []string, error) {
+	domains, err := convert.STIXToDomains(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse stix: %w", err)
+	}
+
+	rows := make([]*importRow, 0, len(domains))
+	for i, domain := range domains {
+		rows = append(rows, &importRow{domain: domain, line: i + 1, record: []string{domain.Domain}})
+	}
+
+	return rows, []string{"record"}, nil
+}
+
+// readDomainsTXT parses a plain list with one domain per line. Blank lines and # comments are skipped, and only the
+// last field of each line is used, so hosts files (e.g. "0.0.0.0 example.com") can be read too.
+func readDomainsTXT(r io.Reader) ([]*importRow, []string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	var rows []*importRow
+
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rows = append(rows, &importRow{
+			domain: &model.DomainSubmission{Domain: fields[len(fields)-1]},
+			line:   line,
+			record: []string{strings.TrimSpace(text)},
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read txt: %w", err)
+	}
+
+	return rows, []string{"record"}, nil
+}
+
+// readDomainsYAML parses a YAML sequence of domains, a mapping with a "domains" sequence, or a stream of domain
+// documents.
+func readDomainsYAML(r io.Reader) ([]*importRow, []string, error) {
+	decoder := yaml.NewDecoder(r)
+
+	var rows []*importRow
+
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, nil, fmt.Errorf("parse yaml: %w", err)
+		}
+
+		node := &document
+		if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+			node = node.Content[0]
+		}
+
+		// Unwrap {domains: [...]}.
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == "domains" && node.Content[i+1].Kind == yaml.SequenceNode {
+					node = node.Content[i+1]
+					break
+				}
+			}
+		}
+
+		elements := []*yaml.Node{node}
+		if node.Kind == yaml.SequenceNode {
+			elements = node.Content
+		}
+
+		for _, element := range elements {
+			record, _ := yaml.Marshal(element)
+			row := &importRow{domain: &model.DomainSubmission{}, line: element.Line, record: []string{strings.TrimSpace(string(record))}}
+
+			if err := element.Decode(row.domain); err != nil {
+				row.reasons = append(row.reasons, "yaml: "+err.Error())
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, []string{"record"}, nil
+}
+
+// inputFormatFromPath guesses the input format from a file's extension, falling back to CSV.
+func inputFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".jsonl", ".ndjson":
+		return "ndjson"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".list", ".txt":
+		return "txt"
+	default:
+		return "csv"
+	}
+}
+
+// applyDomainDefaults fills the empty fields of d with the values from defaults.
+func applyDomainDefaults(d, defaults *model.DomainSubmission) {
+	if defaults == nil {
+		return
+	}
+
+	for _, field := range []struct{ value, fallback *string }{
+		{&d.AbuseType, &defaults.AbuseType},
+		{&d.Activity, &defaults.Activity},
+		{&d.Classification, &defaults.Classification},
+		{&d.Comments, &defaults.Comments},
+		{&d.ReportType, &defaults.ReportType},
+		{&d.Source, &defaults.Source},
+		{&d.SourceName, &defaults.SourceName},
+	} {
+		if *field.value == "" {
+			*field.value = *field.fallback
+		}
+	}
+
+	if d.DateIdentified.IsZero() {
+		d.DateIdentified = defaults.DateIdentified
+	}
+}
+
+// jsonRow decodes a single JSON domain object into a row.
+func jsonRow(record []byte, line int) *importRow {
+	row := &importRow{domain: &model.DomainSubmission{}, line: line, record: []string{string(record)}}
+
+	if err := json.Unmarshal(record, row.domain); err != nil {
+		row.reasons = append(row.reasons, "json: "+err.Error())
+	}
+
+	return row
+}
+
+// validateDomainSubmission normalizes the domain (filling in its SLD, TLD, RootDomain and Subdomain), and checks that
+// each enum field holds one of the model values (normalizing its case).
+func validateDomainSubmission(d *model.DomainSubmission) []string {
+	var reasons []string
+
+	for _, field := range []struct {
+		name    string
+		value   *string
+		allowed []string
+	}{
+		{"abuseType", &d.AbuseType, domainAbuseTypes},
+		{"activity", &d.Activity, domainActivities},
+		{"classification", &d.Classification, domainClassifications},
+		{"providerRating", &d.ProviderRating, organizationRatings},
+		{"providerRole", &d.ProviderRole, organizationRoles},
+		{"reportType", &d.ReportType, domainReportTypes},
+		{"source", &d.Source, domainSources},
+	} {
+		if *field.value == "" {
+			continue
+		}
+
+		value, err := parseEnum(*field.value, field.allowed)
+		if err != nil {
+			reasons = append(reasons, field.name+": "+err.Error())
+			continue
+		}
+
+		*field.value = value
+	}
+
+	if d.Domain == "" {
+		reasons = append(reasons, "domain: missing")
+	} else if err := domainutil.Fill(d); err != nil {
+		reasons = append(reasons, "domain: "+err.Error())
+	}
+
+	if !d.DateIdentified.IsZero() && d.DateIdentified.After(time.Now().Add(24*time.Hour)) {
+		reasons = append(reasons, "dateIdentified: in the future")
+	}
+
+	return reasons
+}
+
+// writeDomainsCSV writes domain submissions to path, using the same columns readDomainsCSV accepts.
+func writeDomainsCSV(path string, domains []*model.DomainSubmission) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("open domains file: %w", err)
+	}
+	defer file.Close()
+
+	columns := typeColumns(reflect.TypeFor[model.DomainSubmission]())
+	w := csv.NewWriter(file)
+
+	if err = w.Write(columns); err != nil {
+		return fmt.Errorf("write domains header: %w", err)
+	}
+
+	for _, domain := range domains {
+		fields := flattenRecord(domain)
+
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = fields[column]
+		}
+
+		if err = w.Write(row); err != nil {
+			return fmt.Errorf("write domain row: %w", err)
+		}
+	}
+
+	w.Flush()
+
+	if err = w.Error(); err != nil {
+		return fmt.Errorf("flush domains file: %w", err)
+	}
+
+	return nil
+}
+
+// writeRejectedCSV writes rejected rows to path, prefixed with their line number and the reasons they were rejected.
+func writeRejectedCSV(path string, header []string, rejected []*importRow) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("open rejects file: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+
+	if err = w.Write(append([]string{"line", "reasons"}, header...)); err != nil {
+		return fmt.Errorf("write rejects header: %w", err)
+	}
+
+	for _, row := range rejected {
+		if err = w.Write(append([]string{strconv.Itoa(row.line), strings.Join(row.reasons, "; ")}, row.record...)); err != nil {
+			return fmt.Errorf("write rejected row: %w", err)
+		}
+	}
+
+	w.Flush()
+
+	if err = w.Error(); err != nil {
+		return fmt.Errorf("flush rejects file: %w", err)
+	}
+
+	return nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%q isn't a boolean", value)
+	}
+
+	return b, nil
+}
+
+// parseDate parses a date in any of dateLayouts, or as a Unix timestamp in seconds.
+func parseDate(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q isn't a recognized date (use e.g. 2006-01-02 or RFC 3339)", value)
+}
+
+// parseEnum returns value, lower-cased, if it's one of allowed.
+func parseEnum(value string, allowed []string) (string, error) {
+	value = strings.ToLower(value)
+
+	if !slices.Contains(allowed, value) {
+		return "", fmt.Errorf("%q isn't one of %s", value, strings.Join(allowed, "|"))
+	}
+
+	return value, nil
+}
+
+// splitMulti splits s by any of the given runes.
+func splitMulti(s string, seps []rune) []string {
+	if s == "" {
+		return nil
+	}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		for _, sep := range seps {
+			if r == sep {
+				return true
+			}
+		}
+		return false
+	})
+
+	trimmed := make([]string, 0, len(fields))
+	for _, v := range fields {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+
+	return trimmed
+}
+
+// inviteImportRow is a single invite read by readInvites. It's also the per-row report printed by "invites import".
+type inviteImportRow struct {
+	Email          string `json:"email" yaml:"email"`
+	FirstName      string `json:"firstName,omitempty" yaml:"firstName,omitempty"`
+	LastName       string `json:"lastName,omitempty" yaml:"lastName,omitempty"`
+	OrganizationID string `json:"organizationID,omitempty" yaml:"organizationID,omitempty"`
+	Reason         string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Role           string `json:"role,omitempty" yaml:"role,omitempty"`
+	Status         string `json:"status,omitempty" yaml:"status,omitempty"`
+	Line           int    `json:"line" yaml:"line"`
+}
+
+// invite converts the row into the invite to create.
+func (r *inviteImportRow) invite() *model.Invite {
+	return &model.Invite{
+		UserEmail:          r.Email,
+		UserFirstName:      r.FirstName,
+		UserLastName:       r.LastName,
+		UserOrganizationID: r.OrganizationID,
+		UserRole:           r.Role,
+	}
+}
+
+// set assigns value to the field matching column (e.g. "First Name", "first_name" or "userFirstName"), reporting
+// whether the column is known.
+func (r *inviteImportRow) set(column, value string) bool {
+	column = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(column)))
+	column = strings.TrimPrefix(column, "user")
+	value = strings.TrimSpace(value)
+
+	switch column {
+	case "email":
+		r.Email = value
+	case "firstname":
+		r.FirstName = value
+	case "lastname":
+		r.LastName = value
+	case "organizationid", "orgid":
+		r.OrganizationID = value
+	case "role":
+		r.Role = value
+	default:
+		return false
+	}
+
+	return true
+}
+
+// validate checks the row's email and role, and that the email isn't repeated (tracked in seen). Problems are added to
+// any Reason the row already has (e.g. from decoding it), so a row rejected while it was read stays rejected.
+func (r *inviteImportRow) validate(seen map[string]bool) {
+	var reasons []string
+	if r.Reason != "" {
+		reasons = append(reasons, r.Reason)
+	}
+
+	if r.Email == "" {
+		reasons = append(reasons, "email: missing")
+	} else if address, err := mail.ParseAddress(r.Email); err != nil || address.Address != r.Email {
+		reasons = append(reasons, "email: invalid address")
+	} else if email := strings.ToLower(r.Email); seen[email] {
+		reasons = append(reasons, "email: duplicate")
+	} else {
+		seen[email] = true
+	}
+
+	if r.Role != "" {
+		if role, err := parseEnum(r.Role, userRoles); err != nil {
+			reasons = append(reasons, "role: "+err.Error())
+		} else {
+			r.Role = role
+		}
+	}
+
+	r.Reason = strings.Join(reasons, "; ")
+}
+
+// readInvites parses invites from a CSV (with a header row), JSON or YAML input, validating each row.
+func readInvites(r io.Reader, inputFormat string) ([]*inviteImportRow, error) {
+	var (
+		rows []*inviteImportRow
+		err  error
+	)
+
+	switch inputFormat {
+	case "", "csv":
+		rows, err = readInvitesCSV(r)
+	case "json", "yaml":
+		// JSON is valid YAML.
+		rows, err = readInvitesYAML(r)
+	default:
+		return nil, fmt.Errorf("unsupported input format %q (csv|json|yaml)", inputFormat)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		row.validate(seen)
+	}
+
+	return rows, nil
+}
+
+func readInvitesCSV(r io.Reader) ([]*inviteImportRow, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1 // Allow variable columns.
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	for i, h := range header {
+		header[i] = strings.TrimPrefix(h, "\ufeff")
+
+		if !new(inviteImportRow).set(header[i], "") {
+			log.Warn().Str("column", h).Msg("Ignoring unknown CSV column")
+		}
+	}
+
+	var rows []*inviteImportRow
+
+	for {
+		rec, rErr := cr.Read()
+		if rErr != nil {
+			if errors.Is(rErr, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("read csv record: %w", rErr)
+		}
+
+		// Skip blank lines.
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := &inviteImportRow{Line: line}
+
+		for i, value := range rec {
+			if i < len(header) {
+				row.set(header[i], value)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readInvitesYAML parses a YAML (or JSON) sequence of invites, or a mapping with an "invites" sequence.
+func readInvitesYAML(r io.Reader) ([]*inviteImportRow, error) {
+	var document yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+
+	node := &document
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	// Unwrap {invites: [...]}.
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "invites" && node.Content[i+1].Kind == yaml.SequenceNode {
+				node = node.Content[i+1]
+				break
+			}
+		}
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return nil, errors.New("expected a sequence of invites")
+	}
+
+	rows := make([]*inviteImportRow, 0, len(node.Content))
+
+	for _, element := range node.Content {
+		row := &inviteImportRow{Line: element.Line}
+
+		var fields map[string]string
+		if err := element.Decode(&fields); err != nil {
+			row.Reason = err.Error()
+		}
+
+		for column, value := range fields {
+			if !row.set(column, value) {
+				log.Warn().Str("field", column).Int("line", element.Line).Msg("Ignoring unknown invite field")
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
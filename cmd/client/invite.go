@@ -1,11 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	dt "github.com/globalcyberalliance/domain-trust-go/v2"
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
+// Statuses reported for each row by "invites import".
+const (
+	inviteStatusFailed   = "failed"
+	inviteStatusInvited  = "invited"
+	inviteStatusRejected = "rejected"
+	inviteStatusValid    = "valid"
+)
+
 func newInvitesCMD() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:    "invites",
@@ -18,10 +35,51 @@ func newInvitesCMD() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newInvitesAcceptCMD())
 	cmd.AddCommand(newInvitesCreateCMD())
 	cmd.AddCommand(newInvitesDeleteCMD())
 	cmd.AddCommand(newInvitesFindCMD())
 	cmd.AddCommand(newInvitesGetCMD())
+	cmd.AddCommand(newInvitesImportCMD())
+
+	return cmd
+}
+
+func newInvitesAcceptCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accept",
+		Short: "Accept an invite and register your account",
+		Long: "Register the invited account using the token from your invite email. The password is read from the " +
+			"terminal (or from stdin, when it's piped), and the new API key is saved to the config.",
+		Example: "  client invites accept :token\n  client invites accept 3f1c9a0e8b7d4c2a",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			password, err := readNewPassword()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read password")
+			}
+
+			user, err := apiClient.AcceptInvite(cmd.Context(), args[0], password)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to accept invite")
+			}
+
+			if user.APIKey == nil || user.User == nil {
+				log.Fatal().Msg("Accepted invite, but the response didn't include an api key; run \"client login\" instead")
+			}
+
+			cfg.APIKey = user.Key
+			cfg.UserEmail = user.Email
+			cfg.UserRole = user.Role
+
+			if err = cfg.Save(); err != nil {
+				log.Fatal().Err(err).Msg("could not save config")
+			}
+
+			log.Info().Msg("Successfully registered " + user.Email + "!")
+			printToConsole("API key set!")
+		},
+	}
 
 	return cmd
 }
@@ -30,7 +88,7 @@ func newInvitesCreateCMD() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "create",
 		Short:   "Create invite",
-		Example: "  client invites create\n  client invites create --email=dev@gcai.dev",
+		Example: "  client invites create --email=dev@gcai.dev\n  client invites create --email=dev@gcai.dev --firstName=Dev --lastName=Ops --role=member",
 		Args:    cobra.ExactArgs(0),
 		PreRun:  adminCheck,
 		Run: func(cmd *cobra.Command, _ []string) {
@@ -64,7 +122,7 @@ func newInvitesCreateCMD() *cobra.Command {
 	cmd.Flags().String("lastName", "", "Set invite's last name")
 	cmd.Flags().String("organizationID", "", "Set invite's organizationID")
 	cmd.Flags().String("role", "", "Set invite's role")
-	_ = markFlagsRequired(cmd, "email", "name")
+	_ = markFlagsRequired(cmd, "email")
 
 	return cmd
 }
@@ -132,3 +190,176 @@ func newInvitesGetCMD() *cobra.Command {
 
 	return cmd
 }
+
+func newInvitesImportCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create invites from a CSV, JSON or YAML file",
+		Long: "Create an invite for each row of a CSV (with a header row), JSON or YAML file. Columns are matched " +
+			"case-insensitively against email, firstName, lastName, organizationID and role. Rows are checked against " +
+			"their organization's user quota (counting existing users and pending invites) before anything is sent, and " +
+			"a report of each row's outcome is printed.",
+		Example: "  client invites import --file=staff.csv\n  client invites import --input-format=yaml < staff.yaml",
+		Args:    cobra.ExactArgs(0),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'dry-run'")
+			}
+
+			inputFile, err := cmd.Flags().GetString("file")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'file'")
+			}
+
+			inputFormat, err := cmd.Flags().GetString("input-format")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'input-format'")
+			}
+
+			input := os.Stdin
+
+			if inputFile != "" && inputFile != "-" {
+				if input, err = os.Open(inputFile); err != nil {
+					log.Fatal().Err(err).Msg("Failed to open input file")
+				}
+				defer input.Close()
+
+				if inputFormat == "" {
+					inputFormat = inputFormatFromPath(inputFile)
+				}
+			}
+
+			rows, err := readInvites(input, strings.ToLower(inputFormat))
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to parse input")
+			}
+
+			if len(rows) == 0 {
+				log.Fatal().Msg("No invites found in input")
+			}
+
+			if err = checkInviteQuotas(cmd.Context(), rows); err != nil {
+				log.Fatal().Err(err).Msg("Failed to check organization user quotas")
+			}
+
+			var created, rejected, failed int
+
+			for _, row := range rows {
+				switch {
+				case row.Reason != "":
+					row.Status = inviteStatusRejected
+					rejected++
+				case dryRun:
+					row.Status = inviteStatusValid
+				default:
+					if cErr := apiClient.CreateInvite(cmd.Context(), row.invite()); cErr != nil {
+						row.Status = inviteStatusFailed
+						row.Reason = cErr.Error()
+						failed++
+
+						continue
+					}
+
+					row.Status = inviteStatusInvited
+					created++
+				}
+			}
+
+			printToConsole(rows)
+
+			if dryRun {
+				log.Info().Int("rowsValid", len(rows)-rejected).Int("rowsRejected", rejected).Msg("Dry run, no invites were created")
+				return
+			}
+
+			if rejected > 0 || failed > 0 {
+				log.Warn().Int("invitesCreated", created).Int("rowsRejected", rejected).Int("invitesFailed", failed).Msg("Some invites weren't created")
+				return
+			}
+
+			log.Info().Int("invitesCreated", created).Msg("Invites created!")
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Validate the input and check quotas without creating any invites")
+	cmd.Flags().String("file", "", "Read invites from this file instead of stdin (the format is guessed from its extension)")
+	cmd.Flags().String("input-format", "", "Set the input format (csv|json|yaml)")
+
+	return cmd
+}
+
+// checkInviteQuotas rejects the valid rows that would take an organization past its user quota (its UserQuota, or
+// OrganizationDefaultUserQuota if it has none), counting its existing users and pending invites. Rows without an
+// organization ID join the inviting admin's organization, which the API checks itself.
+func checkInviteQuotas(ctx context.Context, rows []*inviteImportRow) error {
+	remaining := make(map[string]int)
+
+	for _, row := range rows {
+		if row.Reason != "" || row.OrganizationID == "" {
+			continue
+		}
+
+		if _, ok := remaining[row.OrganizationID]; !ok {
+			organization, err := apiClient.FindOrganizationByID(ctx, row.OrganizationID)
+			if err != nil {
+				var apiErr *dt.APIError
+				if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+					row.Reason = "organizationID: not found"
+					continue
+				}
+
+				return err
+			}
+
+			quota := int(organization.UserQuota)
+			if quota == 0 {
+				quota = model.OrganizationDefaultUserQuota
+			}
+
+			remaining[row.OrganizationID] = quota
+		}
+
+		remaining[row.OrganizationID]--
+	}
+
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	// Only count the users and pending invites of the organizations being invited to.
+	for organizationID := range remaining {
+		for user, err := range apiClient.Users(ctx, &model.UserFilter{OrganizationID: organizationID}) {
+			if err != nil {
+				return fmt.Errorf("count users: %w", err)
+			}
+
+			if user.OrganizationID == organizationID {
+				remaining[organizationID]--
+			}
+		}
+
+		for invite, err := range apiClient.Invites(ctx, &model.InviteFilter{UserOrganizationID: organizationID}) {
+			if err != nil {
+				return fmt.Errorf("count pending invites: %w", err)
+			}
+
+			if invite.UserOrganizationID == organizationID {
+				remaining[organizationID]--
+			}
+		}
+	}
+
+	// Reject rows from the end of the file, so the earlier rows are the ones that fit.
+	for _, row := range slices.Backward(rows) {
+		if row.Reason != "" || row.OrganizationID == "" || remaining[row.OrganizationID] >= 0 {
+			continue
+		}
+
+		row.Reason = "organizationID: user quota exceeded"
+		remaining[row.OrganizationID]++
+	}
+
+	return nil
+}
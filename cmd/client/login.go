@@ -1,7 +1,6 @@
 package main
 
 import (
-	dt "github.com/globalcyberalliance/domain-trust-go/v2"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +26,7 @@ func newLoginCMD() *cobra.Command {
 			if email == "" {
 				apiKey := args[0]
 
-				apiClient = dt.New(apiKey)
+				apiClient.SetAPIKey(apiKey)
 
 				user, err := apiClient.FindSessionUser(cmd.Context())
 				if err != nil {
@@ -43,7 +42,7 @@ func newLoginCMD() *cobra.Command {
 
 				printToConsole("Successfully set api key as " + apiKey)
 			} else {
-				apiClient = dt.New(cfg.APIKey)
+				apiClient.SetAPIKey(cfg.APIKey)
 
 				apiKey, err := apiClient.Login(cmd.Context(), email, password)
 				if err != nil {
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,19 +30,25 @@ var (
 	log                           zerolog.Logger
 	debug, prettyLog, writeToFile bool
 	timeout                       time.Duration
-	format, logLevel              string
+	columns                       []string
+	format, logLevel, profile     string
 	limit                         uint64
+	rateLimit                     float64
 	slash                         = string(os.PathSeparator)
 )
 
 func main() {
 	rootCMD := newRootCMD()
 	rootCMD.AddCommand(newAPIKeysCMD())
+	rootCMD.AddCommand(newAuthCMD())
 	rootCMD.AddCommand(newConfigCMD())
 	rootCMD.AddCommand(newDocsCMD())
 	rootCMD.AddCommand(newDomainsCMD())
 	rootCMD.AddCommand(newInvitesCMD())
 	rootCMD.AddCommand(newLoginCMD())
+	rootCMD.AddCommand(newMetricsCMD())
+	rootCMD.AddCommand(newOptionsCMD())
+	rootCMD.AddCommand(newOrganizationsCMD())
 	rootCMD.AddCommand(newUserCMD())
 	rootCMD.AddCommand(newUsersCMD())
 	rootCMD.AddCommand(newVersionCMD())
@@ -54,7 +63,7 @@ func newRootCMD() *cobra.Command {
 		Use:   "client",
 		Short: "domain-trust Client",
 		Long:  `Interact with the domain-trust API`,
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
 			logger, err := newLogger(logLevel)
 			if err != nil {
 				fmt.Printf("Unable to setup logger: %s: %v\n", logLevel, err)
@@ -73,7 +82,23 @@ func newRootCMD() *cobra.Command {
 				log.Fatal().Err(err).Msg("unable to initialize config")
 			}
 
-			apiClient = dt.New(cfg.APIKey, dt.WithDebug(debug), dt.WithTimeout(defaultTimeout))
+			// Only the commands that store credentials may create a profile; anything else would silently run against
+			// the default endpoint without an API key.
+			createProfile := slices.Contains([]string{"config set", "login"}, strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "))
+
+			if err = cfg.UseProfile(profile, createProfile); err != nil {
+				log.Fatal().Err(err).Msg("unable to use profile")
+			}
+
+			opts := []dt.Option{dt.WithBaseURL(cfg.Endpoint), dt.WithDebug(debug), dt.WithTimeout(defaultTimeout)}
+
+			if rateLimit > 0 {
+				opts = append(opts, dt.WithRateLimit(rateLimit, int(math.Ceil(rateLimit))), dt.WithRateLimitHook(func(e dt.RateLimitEvent) {
+					log.Debug().Str("reason", e.Reason).Dur("wait", e.Wait).Int("remaining", e.Remaining).Msg("Throttling requests")
+				}))
+			}
+
+			apiClient = dt.New(cfg.APIKey, opts...)
 		},
 		Version: dt.Version,
 	}
@@ -81,10 +106,13 @@ func newRootCMD() *cobra.Command {
 	cmd.PersistentFlags().String("createdAfter", "", "Only return results created after this date")
 	cmd.PersistentFlags().String("createdBefore", "", "Only return results created before this date")
 	cmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable console debugging")
-	cmd.PersistentFlags().StringVarP(&format, "format", "f", "yaml", "Set the output format for CLI commands (json, jsonp, yaml)")
+	cmd.PersistentFlags().StringSliceVar(&columns, "columns", nil, "Set the columns for csv, tsv and list output (e.g. domain,classification,urls,whitelist.providerName)")
+	cmd.PersistentFlags().StringVarP(&format, "format", "f", "yaml", "Set the output format for CLI commands (cbor, csv, json, jsonp, list, ndjson, tsv, yaml)")
 	cmd.PersistentFlags().Uint64VarP(&limit, "limit", "l", 0, "Limit the quantity of returned results")
 	cmd.PersistentFlags().StringVar(&logLevel, "logLevel", "info", "Set log level (debug, info, warn, error, fatal, panic)")
 	cmd.PersistentFlags().BoolVar(&prettyLog, "prettyLog", true, "Pretty print logs to console")
+	cmd.PersistentFlags().StringVar(&profile, "profile", os.Getenv("DT_PROFILE"), "Use a named config profile (e.g. staging)")
+	cmd.PersistentFlags().Float64Var(&rateLimit, "rateLimit", 0, "Limit API requests per second, following the server's rate limit headers (0 disables)")
 	cmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", defaultTimeout, "Specify the API HTTP timeout")
 	cmd.PersistentFlags().BoolVarP(&writeToFile, "writetofile", "w", false, "Write the output to a file")
 
@@ -118,6 +146,10 @@ func marshal(data any) []byte {
 		output, err = json.Marshal(data)
 	case "jsonp":
 		output, err = json.MarshalIndent(data, "", "\t")
+	case "csv", "list", "ndjson", "tsv":
+		var buf bytes.Buffer
+		err = writeRecords(&buf, data)
+		output = buf.Bytes()
 	default:
 		output, err = yaml.Marshal(data)
 	}
@@ -139,9 +171,9 @@ func newLogger(logLevel string) (zerolog.Logger, error) {
 	var logWriter io.Writer
 
 	if prettyLog {
-		logWriter = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+		logWriter = zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
 	} else {
-		logWriter = os.Stdout
+		logWriter = os.Stderr
 	}
 
 	logger = zerolog.New(logWriter).With().Timestamp().Logger().Level(logLevelParsed)
@@ -149,14 +181,23 @@ func newLogger(logLevel string) (zerolog.Logger, error) {
 	return logger, nil
 }
 
+// outputFilename returns a timestamped filename for -w output, using the extension of the selected format.
+func outputFilename() string {
+	extension := strings.ToLower(format)
+
+	switch extension {
+	case "jsonp":
+		extension = "json"
+	case "list":
+		extension = "txt"
+	}
+
+	return cast.ToString(time.Now().Unix()) + "." + extension
+}
+
 func printToConsole(data any) {
 	if writeToFile {
-		extension := format
-		if extension == "jsonp" {
-			extension = "json"
-		}
-
-		filename := cast.ToString(time.Now().Unix()) + "." + extension
+		filename := outputFilename()
 
 		if err := printToFile(data, filename); err != nil {
 			log.Fatal().Err(err).Msg("Failed to write output to file")
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+)
+
+const (
+	metricsBarWidth      = 40
+	metricsViewRaw       = "raw"
+	metricsViewSparkline = "sparkline"
+	metricsViewTable     = "table"
+)
+
+// sparkTicks are the glyphs used to draw sparklines, from lowest to highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+func newMetricsCMD() *cobra.Command {
+	var public bool
+	var view string
+
+	cmd := &cobra.Command{
+		Use:     "metrics",
+		Short:   "Show dashboard metrics",
+		Example: "  client metrics\n  client metrics --view=sparkline\n  client metrics --public --view=raw -f json",
+		Args:    cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			view = strings.ToLower(view)
+
+			switch view {
+			case metricsViewRaw, metricsViewSparkline, metricsViewTable:
+			default:
+				log.Fatal().Msg("Unknown view " + view + " (table|sparkline|raw)")
+			}
+
+			var sb strings.Builder
+
+			if public {
+				metrics, err := apiClient.FindPublicMetrics(cmd.Context())
+				if err != nil {
+					log.Fatal().Err(err).Msg("Failed to find public metrics")
+				}
+
+				if view == metricsViewRaw {
+					printToConsole(metrics)
+					return
+				}
+
+				writeMetricsTotals(&sb, [][2]string{
+					{"Unique domains", cast.ToString(metrics.UniqueDomains)},
+					{"Submissions", cast.ToString(metrics.Submissions)},
+					{"Total partners", cast.ToString(metrics.TotalPartners)},
+				})
+				writeTimeCounts(&sb, "Daily domains (30d)", metrics.DailyDomains30d, view)
+				writeTimeCounts(&sb, "Daily submissions (30d)", metrics.DailySubmissions30d, view)
+			} else {
+				metrics, err := apiClient.FindDashboardMetrics(cmd.Context())
+				if err != nil {
+					log.Fatal().Err(err).Msg("Failed to find dashboard metrics")
+				}
+
+				if view == metricsViewRaw {
+					printToConsole(metrics)
+					return
+				}
+
+				writeMetricsTotals(&sb, [][2]string{
+					{"Unique domains", cast.ToString(metrics.UniqueDomains)},
+					{"Submissions", cast.ToString(metrics.Submissions)},
+					{"Total partners", cast.ToString(metrics.TotalPartners)},
+					{"Active partners (30d)", cast.ToString(metrics.ActivePartners30d)},
+					{"Blocked", fmt.Sprintf("%.2f%%", metrics.PctBlocked)},
+				})
+				writeKVs(&sb, "Domains by provider (30d)", metrics.DomainsByProvider30d, view)
+				writeKVs(&sb, "Submissions by provider (30d)", metrics.SubmissionsByProvider30d, view)
+				writeTimeCounts(&sb, "Daily domains (30d)", metrics.DailyDomains30d, view)
+				writeTimeCounts(&sb, "Daily submissions (30d)", metrics.DailySubmissions30d, view)
+			}
+
+			if writeToFile {
+				filename := cast.ToString(time.Now().Unix()) + ".txt"
+
+				if err := os.WriteFile(filename, []byte(sb.String()), 0o600); err != nil {
+					log.Fatal().Err(err).Msg("Failed to write output to file")
+				}
+
+				log.Info().Msg("Output written to " + filename)
+
+				return
+			}
+
+			_, _ = io.WriteString(os.Stdout, sb.String())
+		},
+	}
+
+	cmd.Flags().BoolVar(&public, "public", false, "Show the public metrics instead of the partner dashboard metrics")
+	cmd.Flags().StringVar(&view, "view", metricsViewTable, "How to display the metrics (table|sparkline|raw)")
+
+	return cmd
+}
+
+// sparkline renders the given values as a single line of block glyphs scaled between their min and max.
+func sparkline(values []uint64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lowest, highest := slices.Min(values), slices.Max(values)
+	spread := highest - lowest
+
+	var sb strings.Builder
+
+	for _, v := range values {
+		tick := len(sparkTicks) - 1
+		if spread > 0 {
+			tick = int((v - lowest) * uint64(len(sparkTicks)-1) / spread)
+		}
+
+		sb.WriteRune(sparkTicks[tick])
+	}
+
+	return sb.String()
+}
+
+func writeKVs(w io.Writer, title string, kvs []model.KV, view string) {
+	sorted := slices.Clone(kvs)
+	slices.SortStableFunc(sorted, func(a, b model.KV) int {
+		return cmp.Compare(b.Value, a.Value)
+	})
+
+	_, _ = fmt.Fprintf(w, "\n%s\n", title)
+
+	if len(sorted) == 0 {
+		_, _ = fmt.Fprintln(w, "  (no data)")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // Column padding.
+
+	if view == metricsViewTable {
+		_, _ = fmt.Fprintln(tw, "  PROVIDER\tCOUNT")
+		for _, kv := range sorted {
+			_, _ = fmt.Fprintf(tw, "  %s\t%d\n", kv.Key, kv.Value)
+		}
+	} else {
+		highest := sorted[0].Value
+		for _, kv := range sorted {
+			width := 0
+			if highest > 0 {
+				width = int(kv.Value * metricsBarWidth / highest)
+			}
+
+			_, _ = fmt.Fprintf(tw, "  %s\t%s %d\n", kv.Key, strings.Repeat(string(sparkTicks[len(sparkTicks)-1]), width), kv.Value)
+		}
+	}
+
+	_ = tw.Flush()
+}
+
+func writeMetricsTotals(w io.Writer, totals [][2]string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // Column padding.
+
+	for _, total := range totals {
+		_, _ = fmt.Fprintf(tw, "%s:\t%s\n", total[0], total[1])
+	}
+
+	_ = tw.Flush()
+}
+
+func writeTimeCounts(w io.Writer, title string, counts []model.TimeCount, view string) {
+	sorted := slices.Clone(counts)
+	slices.SortStableFunc(sorted, func(a, b model.TimeCount) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	_, _ = fmt.Fprintf(w, "\n%s\n", title)
+
+	if len(sorted) == 0 {
+		_, _ = fmt.Fprintln(w, "  (no data)")
+		return
+	}
+
+	if view == metricsViewTable {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // Column padding.
+
+		_, _ = fmt.Fprintln(tw, "  DATE\tCOUNT")
+		for _, count := range sorted {
+			_, _ = fmt.Fprintf(tw, "  %s\t%d\n", count.Date.Format(time.DateOnly), count.Count)
+		}
+
+		_ = tw.Flush()
+
+		return
+	}
+
+	values := make([]uint64, 0, len(sorted))
+	var total uint64
+
+	for _, count := range sorted {
+		values = append(values, count.Count)
+		total += count.Count
+	}
+
+	_, _ = fmt.Fprintf(w, "  %s → %s  %s\n", sorted[0].Date.Format(time.DateOnly), sorted[len(sorted)-1].Date.Format(time.DateOnly), sparkline(values))
+	_, _ = fmt.Fprintf(w, "  min=%d max=%d total=%d\n", slices.Min(values), slices.Max(values), total)
+}
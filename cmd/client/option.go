@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newOptionsCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "options",
+		Short:  "Interact with platform options",
+		PreRun: adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := cmd.Help(); err != nil {
+				panic(err)
+			}
+		},
+	}
+
+	cmd.AddCommand(newOptionsGetCMD())
+	cmd.AddCommand(newOptionsListCMD())
+	cmd.AddCommand(newOptionsSetCMD())
+
+	return cmd
+}
+
+func newOptionsGetCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Get option",
+		Example: "  client options get :key\n  client options get registrationEnabled",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			option, err := apiClient.FindOptionByKey(cmd.Context(), args[0])
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get option " + args[0])
+			}
+
+			printToConsole(option)
+		},
+	}
+
+	return cmd
+}
+
+func newOptionsListCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"find"},
+		Short:   "List options",
+		Args:    cobra.ExactArgs(0),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			options, err := apiClient.FindOptions(cmd.Context(), &model.OptionFilter{})
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to find options")
+			}
+
+			if len(options) == 0 {
+				log.Warn().Msg("No options found")
+				return
+			}
+
+			printToConsole(options)
+		},
+	}
+
+	return cmd
+}
+
+func newOptionsSetCMD() *cobra.Command {
+	var showDiff, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set option",
+		Long: "Set an option's value. With --diff, the change is previewed against the current value and must be " +
+			"confirmed before it's applied (pass --yes to skip the confirmation).",
+		Example: "  client options set :key :value\n  client options set registrationEnabled false --diff",
+		Args:    cobra.ExactArgs(2), //nolint:mnd // Key and value.
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			key, value := args[0], args[1]
+
+			if showDiff {
+				current, err := apiClient.FindOptionByKey(cmd.Context(), key)
+				if err != nil {
+					log.Fatal().Err(err).Msg("Failed to get option " + key)
+				}
+
+				if current.Value == value {
+					log.Info().Msg("Option " + key + " is already set to this value")
+					return
+				}
+
+				_, _ = fmt.Fprintf(os.Stderr, "--- %s (current)\n+++ %s (new)\n%s\n", key, key, diffLines(current.Value, value))
+
+				if !yes && !confirm("Apply this change?") {
+					log.Warn().Msg("Option not changed")
+					return
+				}
+			}
+
+			option, err := apiClient.UpdateOption(cmd.Context(), key, &model.OptionUpdate{Value: &value})
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to update option " + key)
+			}
+
+			printToConsole(option)
+		},
+	}
+
+	cmd.Flags().BoolVar(&showDiff, "diff", false, "Preview the change and confirm it before it's applied")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply the change without asking for confirmation")
+
+	return cmd
+}
+
+// confirm asks a yes/no question on the terminal. It returns false when stdin isn't a terminal.
+func confirm(question string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Warn().Msg("Can't ask for confirmation without a terminal (pass --yes to skip it)")
+		return false
+	}
+
+	_, _ = fmt.Fprint(os.Stderr, question+" [y/N] ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
+// diffLines returns a line-by-line diff of two values, prefixing removed lines with "-", added lines with "+" and
+// unchanged lines with a space.
+func diffLines(from, to string) string {
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+
+	// lcs[i][j] holds the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff strings.Builder
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+
+	return strings.TrimSuffix(diff.String(), "\n")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newOrganizationsCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "organizations",
+		Aliases: []string{"orgs"},
+		Short:   "Interact with organizations",
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := cmd.Help(); err != nil {
+				panic(err)
+			}
+		},
+	}
+
+	cmd.AddCommand(newOrganizationsCreateCMD())
+	cmd.AddCommand(newOrganizationsDeleteCMD())
+	cmd.AddCommand(newOrganizationsFindCMD())
+	cmd.AddCommand(newOrganizationsGetCMD())
+	cmd.AddCommand(newOrganizationsUpdateCMD())
+
+	return cmd
+}
+
+func newOrganizationsCreateCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create organization",
+		Example: "  client organizations create --name=GCA --rating=high-confidence --role=other",
+		Args:    cobra.ExactArgs(0),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var organization model.Organization
+
+			cmd.Flags().Visit(func(flag *pflag.Flag) {
+				switch flag.Name {
+				case "name":
+					organization.Name = flag.Value.String()
+				case "rating":
+					organization.Rating = flag.Value.String()
+				case "role":
+					organization.Role = flag.Value.String()
+				case "status":
+					organization.Status = flag.Value.String()
+				case "userQuota":
+					userQuota, err := cast.ToInt8E(flag.Value.String())
+					if err != nil {
+						log.Fatal().Err(err).Msg("Failed to parse user quota")
+					}
+
+					organization.UserQuota = userQuota
+				}
+			})
+
+			created, err := apiClient.CreateOrganization(cmd.Context(), &organization)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to create organization")
+			}
+
+			printToConsole(created)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Set organization's name")
+	cmd.Flags().String("rating", "", "Set organization's rating (trial|predictive|low-confidence|med-confidence|high-confidence)")
+	cmd.Flags().String("role", "", "Set organization's role (registrar|registry|reseller|other|icann)")
+	cmd.Flags().String("status", "", "Set organization's status (active|deactivated)")
+	cmd.Flags().Int8("userQuota", 0, "Set organization's user quota (defaults to "+cast.ToString(model.OrganizationDefaultUserQuota)+")")
+	_ = markFlagsRequired(cmd, "name", "rating", "role")
+
+	return cmd
+}
+
+func newOrganizationsDeleteCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete",
+		Short:   "Delete organization",
+		Example: "  client organizations delete :id\n  client organizations delete 019a0dd4-11a5-7477-91a8-538b1bc334e4",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := apiClient.DeleteOrganization(cmd.Context(), args[0]); err != nil {
+				log.Fatal().Err(err).Msg("Failed to delete organization")
+			}
+
+			log.Info().Msg("Successfully deleted organization!")
+		},
+	}
+
+	return cmd
+}
+
+func newOrganizationsFindCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "find",
+		Short:  "Find organizations",
+		PreRun: adminCheck,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var filter model.OrganizationFilter
+
+			if err := unmarshalFlags(cmd, &filter); err != nil {
+				log.Fatal().Err(err).Msg("Failed to unmarshal flags")
+			}
+
+			findAll, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get flag 'all'")
+			}
+
+			var organizations []*model.Organization
+
+			if !findAll {
+				organizations, err = apiClient.FindOrganizations(cmd.Context(), &filter)
+				if err != nil {
+					log.Fatal().Err(err).Msg("Failed to find organizations")
+				}
+			} else {
+				filter.MetadataFilter.Limit = model.MaxMetadataLimit
+
+				organizationIterator, iErr := apiClient.FindOrganizationsPaged(cmd.Context(), &filter)
+				if iErr != nil {
+					log.Fatal().Err(iErr).Msg("Failed to find all organizations")
+				}
+
+				for organizationIterator.Next() {
+					organizations = append(organizations, organizationIterator.Value())
+				}
+
+				if organizationIterator.Err() != nil {
+					log.Fatal().Err(organizationIterator.Err()).Msg("Failed to page organizations")
+				}
+			}
+
+			if len(organizations) == 0 {
+				log.Warn().Msg("No organizations found")
+				return
+			}
+
+			printToConsole(organizations)
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Automatically paginate through the results")
+	cmd.Flags().String("name", "", "Filter organizations by name")
+	cmd.Flags().String("rating", "", "Filter organizations by rating (trial|predictive|low-confidence|med-confidence|high-confidence)")
+	cmd.Flags().String("role", "", "Filter organizations by role (registrar|registry|reseller|other|icann)")
+	cmd.Flags().String("status", "", "Filter organizations by status (active|deactivated)")
+
+	return cmd
+}
+
+func newOrganizationsGetCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Get organization",
+		Example: "  client organizations get :id\n  client organizations get 019a0dd4-11a5-7477-91a8-538b1bc334e4",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			organization, err := apiClient.FindOrganizationByID(cmd.Context(), args[0])
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to get organization " + args[0])
+			}
+
+			printToConsole(organization)
+		},
+	}
+
+	return cmd
+}
+
+func newOrganizationsUpdateCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "update",
+		Short:   "Update organization",
+		Example: "  client organizations update :id\n  client organizations update 019a0dd4-11a5-7477-91a8-538b1bc334e4 --status=deactivated",
+		Args:    cobra.ExactArgs(1),
+		PreRun:  adminCheck,
+		Run: func(cmd *cobra.Command, args []string) {
+			var update model.OrganizationUpdate
+
+			cmd.Flags().Visit(func(flag *pflag.Flag) {
+				val := flag.Value.String()
+				switch flag.Name {
+				case "name":
+					update.Name = &val
+				case "rating":
+					update.Rating = &val
+				case "role":
+					update.Role = &val
+				case "status":
+					update.Status = &val
+				case "userQuota":
+					userQuota, err := cast.ToInt8E(val)
+					if err != nil {
+						log.Fatal().Err(err).Msg("Failed to parse user quota")
+					}
+
+					update.UserQuota = &userQuota
+				}
+			})
+
+			organization, err := apiClient.UpdateOrganization(cmd.Context(), args[0], &update)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to update organization")
+			}
+
+			printToConsole(organization)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Update organization's name")
+	cmd.Flags().String("rating", "", "Update organization's rating (trial|predictive|low-confidence|med-confidence|high-confidence)")
+	cmd.Flags().String("role", "", "Update organization's role (registrar|registry|reseller|other|icann)")
+	cmd.Flags().String("status", "", "Update organization's status (active|deactivated)")
+	cmd.Flags().Int8("userQuota", 0, "Update organization's user quota")
+
+	return cmd
+}
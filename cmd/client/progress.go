@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+const progressBarWidth = 40
+
+// progressBar renders a single-line progress bar to stderr. When stderr isn't a terminal (e.g. it's redirected to a
+// log file), it logs each update instead.
+type progressBar struct {
+	w        io.Writer
+	label    string
+	mu       sync.Mutex
+	terminal bool
+}
+
+func newProgressBar(label string) *progressBar {
+	return &progressBar{
+		w:        os.Stderr,
+		label:    label,
+		terminal: isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd()),
+	}
+}
+
+// Finish ends the progress bar's line, so later output starts on a fresh one.
+func (p *progressBar) Finish() {
+	if p.terminal {
+		_, _ = fmt.Fprintln(p.w)
+	}
+}
+
+// Update redraws the bar with the current progress.
+func (p *progressBar) Update(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.terminal {
+		log.Info().Int("done", done).Int("total", total).Msg(p.label)
+		return
+	}
+
+	filled := progressBarWidth
+	percent := 100
+
+	if total > 0 {
+		filled = progressBarWidth * done / total
+		percent = 100 * done / total
+	}
+
+	_, _ = fmt.Fprintf(p.w, "\r%s [%s%s] %d/%d (%d%%)",
+		p.label, strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), done, total, percent)
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDomainColumns are the csv, tsv and list columns written for domains when --columns isn't set.
+var defaultDomainColumns = []string{
+	"id", "created", "domain", "sld", "tld", "rootDomain", "subDomain", "registrationDate",
+	"providerID", "providerName", "providerRating", "providerRole",
+	"abuseType", "activity", "classification", "comments", "dateIdentified", "isBlocked", "reportType", "source", "sourceName", "urls",
+	"otherProviders.providerName", "whitelist.providerName",
+}
+
+type (
+	// recordWriter streams records to an output one at a time, so they never need to be held in memory together. Flush
+	// writes out what's buffered so far, and Close finishes the output (e.g. closing a JSON array) and flushes it.
+	recordWriter interface {
+		Write(record any) error
+		Flush() error
+		Close() error
+	}
+
+	cborRecordWriter struct {
+		buf *bufio.Writer
+		enc *cbor.Encoder
+	}
+
+	csvRecordWriter struct {
+		columns []string
+		w       *csv.Writer
+		header  bool
+	}
+
+	// jsonArrayRecordWriter streams records as the elements of a single JSON array.
+	jsonArrayRecordWriter struct {
+		buf    *bufio.Writer
+		indent string
+		// open is true once the opening bracket has been written (or, when appending, was written by an earlier run).
+		open bool
+		// started is false until the first element is written, so it isn't preceded by a comma.
+		started bool
+	}
+
+	jsonRecordWriter struct {
+		buf *bufio.Writer
+		enc *json.Encoder
+	}
+
+	listRecordWriter struct {
+		buf    *bufio.Writer
+		column string
+	}
+
+	yamlRecordWriter struct {
+		buf *bufio.Writer
+	}
+)
+
+// newRecordWriter returns a recordWriter for the selected output format: a JSON array for json (indented for jsonp),
+// one JSON object per line for ndjson, a YAML document stream for yaml, a CBOR sequence for cbor, CSV/TSV rows for
+// csv/tsv, and one value per line (the domain, or the first column) for list. When header is false (e.g. when appending
+// to an existing file), CSV/TSV output omits its header row, and a JSON array is continued rather than opened.
+func newRecordWriter(w io.Writer, cols []string, header bool) recordWriter {
+	buf := bufio.NewWriter(w)
+
+	switch strings.ToLower(format) {
+	case "cbor":
+		return &cborRecordWriter{buf: buf, enc: cbor.NewEncoder(buf)}
+	case "csv", "tsv":
+		csvWriter := csv.NewWriter(w)
+		if strings.EqualFold(format, "tsv") {
+			csvWriter.Comma = '\t'
+		}
+
+		return &csvRecordWriter{columns: cols, w: csvWriter, header: header}
+	case "list":
+		column := "domain"
+		if len(cols) > 0 && !slices.Contains(cols, column) {
+			column = cols[0]
+		}
+
+		return &listRecordWriter{buf: buf, column: column}
+	case "json":
+		return &jsonArrayRecordWriter{buf: buf, open: !header, started: !header}
+	case "jsonp":
+		return &jsonArrayRecordWriter{buf: buf, indent: "\t", open: !header, started: !header}
+	case "ndjson":
+		return &jsonRecordWriter{buf: buf, enc: json.NewEncoder(buf)}
+	default:
+		return &yamlRecordWriter{buf: buf}
+	}
+}
+
+func (r *cborRecordWriter) Close() error {
+	return r.Flush()
+}
+
+func (r *cborRecordWriter) Flush() error {
+	return r.buf.Flush()
+}
+
+func (r *cborRecordWriter) Write(record any) error {
+	return r.enc.Encode(record)
+}
+
+func (r *csvRecordWriter) Close() error {
+	return r.Flush()
+}
+
+func (r *csvRecordWriter) Flush() error {
+	r.w.Flush()
+
+	return r.w.Error()
+}
+
+func (r *csvRecordWriter) Write(record any) error {
+	if r.header {
+		r.header = false
+
+		if err := r.w.Write(r.columns); err != nil {
+			return fmt.Errorf("write csv header: %w", err)
+		}
+	}
+
+	fields := flattenRecord(record)
+
+	row := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		row[i] = fields[column]
+	}
+
+	return r.w.Write(row)
+}
+
+func (r *jsonArrayRecordWriter) Close() error {
+	closing := "]\n"
+
+	switch {
+	case !r.open:
+		closing = "[]\n"
+	case r.started && r.indent != "":
+		closing = "\n]\n"
+	}
+
+	if _, err := r.buf.WriteString(closing); err != nil {
+		return fmt.Errorf("close json array: %w", err)
+	}
+
+	return r.Flush()
+}
+
+func (r *jsonArrayRecordWriter) Flush() error {
+	return r.buf.Flush()
+}
+
+func (r *jsonArrayRecordWriter) Write(record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	if r.indent != "" {
+		var indented bytes.Buffer
+		if err = json.Indent(&indented, data, r.indent, r.indent); err != nil {
+			return fmt.Errorf("indent json: %w", err)
+		}
+
+		data = indented.Bytes()
+	}
+
+	separator := ","
+	if !r.open {
+		separator = "["
+	} else if !r.started {
+		separator = ""
+	}
+
+	if r.indent != "" {
+		separator += "\n" + r.indent
+	}
+
+	r.open, r.started = true, true
+
+	if _, err = r.buf.WriteString(separator); err != nil {
+		return fmt.Errorf("write json: %w", err)
+	}
+
+	if _, err = r.buf.Write(data); err != nil {
+		return fmt.Errorf("write json: %w", err)
+	}
+
+	return nil
+}
+
+func (r *jsonRecordWriter) Close() error {
+	return r.Flush()
+}
+
+func (r *jsonRecordWriter) Flush() error {
+	return r.buf.Flush()
+}
+
+func (r *jsonRecordWriter) Write(record any) error {
+	return r.enc.Encode(record)
+}
+
+func (r *listRecordWriter) Close() error {
+	return r.Flush()
+}
+
+func (r *listRecordWriter) Flush() error {
+	return r.buf.Flush()
+}
+
+func (r *listRecordWriter) Write(record any) error {
+	value := flattenRecord(record)[r.column]
+	if value == "" {
+		return nil
+	}
+
+	if _, err := r.buf.WriteString(value + "\n"); err != nil {
+		return fmt.Errorf("write list: %w", err)
+	}
+
+	return nil
+}
+
+func (r *yamlRecordWriter) Close() error {
+	return r.Flush()
+}
+
+func (r *yamlRecordWriter) Flush() error {
+	return r.buf.Flush()
+}
+
+func (r *yamlRecordWriter) Write(record any) error {
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal yaml: %w", err)
+	}
+
+	if _, err = r.buf.WriteString("---\n"); err != nil {
+		return fmt.Errorf("write yaml separator: %w", err)
+	}
+
+	if _, err = r.buf.Write(data); err != nil {
+		return fmt.Errorf("write yaml: %w", err)
+	}
+
+	return nil
+}
+
+// outputColumns returns the columns to write for records of type t: the --columns flag if set, the default domain
+// columns for domains, or every top-level field otherwise. Nested fields (e.g. "whitelist.providerName") can always be
+// selected with --columns.
+func outputColumns(t reflect.Type) []string {
+	if len(columns) > 0 {
+		return columns
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeFor[model.Domain]() {
+		return defaultDomainColumns
+	}
+
+	return typeColumns(t)
+}
+
+// typeColumns lists the json tag names of a struct type's scalar fields, merging in embedded structs.
+func typeColumns(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			names = append(names, typeColumns(field.Type)...)
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Map || (field.Type.Kind() == reflect.Slice && isStructLike(field.Type.Elem())) {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// writeRecords writes data (a single value or a slice of values) through a recordWriter. Values that aren't structs
+// (e.g. plain strings) are written one per line.
+func writeRecords(w io.Writer, data any) error {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+
+	var records []reflect.Value
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := range v.Len() {
+			records = append(records, v.Index(i))
+		}
+	} else if v.IsValid() {
+		records = append(records, v)
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if !isStructLike(records[0].Type()) && strings.ToLower(format) != "ndjson" {
+		for _, record := range records {
+			if _, err := io.WriteString(w, formatField(record)+"\n"); err != nil {
+				return fmt.Errorf("write value: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	writer := newRecordWriter(w, outputColumns(records[0].Type()), true)
+
+	for _, record := range records {
+		if err := writer.Write(record.Interface()); err != nil {
+			return fmt.Errorf("write record: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("flush records: %w", err)
+	}
+
+	return nil
+}
+
+// flattenRecord converts a struct into a map of column name to string value, keyed by json tag name. Embedded structs
+// are merged in, and slices of structs become one column per nested field (e.g. "whitelist.providerName"), with the
+// values of each element joined by semicolons.
+func flattenRecord(record any) map[string]string {
+	fields := make(map[string]string)
+
+	flattenValue(reflect.ValueOf(record), "", fields)
+
+	return fields
+}
+
+func flattenValue(v reflect.Value, prefix string, fields map[string]string) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			flattenValue(value, prefix, fields)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		key := prefix + name
+
+		if value.Kind() == reflect.Slice && isStructLike(value.Type().Elem()) {
+			nested := make(map[string][]string)
+
+			for j := range value.Len() {
+				element := make(map[string]string)
+				flattenValue(value.Index(j), "", element)
+
+				for k, val := range element {
+					nested[k] = append(nested[k], val)
+				}
+			}
+
+			for k, values := range nested {
+				fields[key+"."+k] = strings.Join(values, ";")
+			}
+
+			continue
+		}
+
+		fields[key] = formatField(value)
+	}
+}
+
+func formatField(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(time.RFC3339)
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, v.Len())
+		for i := range v.Len() {
+			values = append(values, formatField(v.Index(i)))
+		}
+
+		return strings.Join(values, ";")
+	}
+
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func isStructLike(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
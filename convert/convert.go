@@ -0,0 +1,132 @@
+package convert
+
+import (
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // UUIDv5 is defined in terms of SHA-1.
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+// ProducerName is the name used for the identity that produces exported intelligence.
+const ProducerName = "Domain Trust (Global Cyber Alliance)"
+
+var (
+	// classificationConfidence is the base confidence (0-100) for each classification.
+	classificationConfidence = map[string]float64{
+		model.DomainClassificationDefinitelyMalicious: 90,
+		model.DomainClassificationProbablyMalicious:   70,
+		model.DomainClassificationPossiblyMalicious:   40,
+		model.DomainClassificationDefinitelyClean:     90,
+	}
+
+	// ratingWeight scales the classification confidence by how much the provider is trusted.
+	ratingWeight = map[string]float64{
+		model.OrganizationRatingHighConfidence: 1,
+		model.OrganizationRatingMedConfidence:  0.85,
+		model.OrganizationRatingLowConfidence:  0.7,
+		model.OrganizationRatingPredictive:     0.6,
+		model.OrganizationRatingTrial:          0.5,
+	}
+)
+
+// Confidence returns a 0-100 confidence score for a submission, derived from its classification and the rating of
+// the provider that submitted it. Unknown classifications score 50, and unknown ratings are weighted at 0.75.
+func Confidence(submission *model.DomainSubmission) int {
+	base, ok := classificationConfidence[submission.Classification]
+	if !ok {
+		base = 50
+	}
+
+	weight, ok := ratingWeight[submission.ProviderRating]
+	if !ok {
+		weight = 0.75
+	}
+
+	return int(math.Round(base * weight))
+}
+
+// firstSeen returns the earliest meaningful timestamp for a submission: when it was identified, falling back to when
+// it was submitted.
+func firstSeen(submission *model.DomainSubmission) time.Time {
+	if !submission.DateIdentified.IsZero() {
+		return submission.DateIdentified.UTC()
+	}
+
+	if !submission.Created.IsZero() {
+		return submission.Created.UTC()
+	}
+
+	return time.Now().UTC()
+}
+
+// isMalicious reports whether a submission flags the domain as (possibly) malicious.
+func isMalicious(submission *model.DomainSubmission) bool {
+	return submission.Classification != model.DomainClassificationDefinitelyClean
+}
+
+// submissions returns the domain's own submission followed by those from other providers.
+func submissions(domain *model.Domain) []*model.DomainSubmission {
+	out := make([]*model.DomainSubmission, 0, len(domain.OtherProviders)+1)
+	out = append(out, &domain.DomainSubmission)
+
+	for _, other := range domain.OtherProviders {
+		if other != nil {
+			out = append(out, other)
+		}
+	}
+
+	return out
+}
+
+// uuidV4 returns a random UUID, formatted as a string.
+func uuidV4() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+
+	u[6] = (u[6] & 0x0f) | 0x40 //nolint:mnd // Version 4.
+	u[8] = (u[8] & 0x3f) | 0x80 //nolint:mnd // RFC 4122 variant.
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// uuidV5 returns a name-based (SHA-1) UUID, formatted as a string.
+func uuidV5(namespace [16]byte, name string) string {
+	h := sha1.New() //nolint:gosec // UUIDv5 is defined in terms of SHA-1.
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+
+	var u [16]byte
+	copy(u[:], h.Sum(nil))
+
+	u[6] = (u[6] & 0x0f) | 0x50 //nolint:mnd // Version 5.
+	u[8] = (u[8] & 0x3f) | 0x80 //nolint:mnd // RFC 4122 variant.
+
+	buf := make([]byte, 36) //nolint:mnd // Canonical UUID length.
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf)
+}
+
+// parseUUID parses a canonical UUID string. It panics on malformed input, so it's only used for constants.
+func parseUUID(s string) [16]byte {
+	var u [16]byte
+
+	if _, err := hex.Decode(u[:], []byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		panic(err)
+	}
+
+	return u
+}
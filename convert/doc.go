@@ -0,0 +1,2 @@
+// Package convert maps Domain Trust models to and from threat intelligence sharing formats, such as STIX 2.1 and MISP.
+package convert
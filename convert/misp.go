@@ -0,0 +1,274 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+const (
+	MISPAnalysisCompleted     = "2"
+	MISPCategoryNetwork       = "Network activity"
+	MISPDistributionOrgOnly   = "0"
+	MISPSightingTypeSighting  = "0"
+	MISPTagNamespace          = "domain-trust"
+	MISPThreatLevelMedium     = "2"
+	MISPAttributeTypeDomain   = "domain"
+	MISPAttributeTypeURL      = "url"
+	mispFirstSeenTimestampFmt = time.RFC3339
+)
+
+// mispNamespace is used to derive stable attribute UUIDs, so re-exporting the same domain produces the same attributes.
+var mispNamespace = parseUUID("9e3c1f52-7a4b-4d6e-8f10-2b5c7d9e0a14")
+
+type (
+	// MISPAttribute is a single MISP event attribute.
+	MISPAttribute struct {
+		UUID         string         `json:"uuid"`
+		Type         string         `json:"type"`
+		Category     string         `json:"category"`
+		Value        string         `json:"value"`
+		Comment      string         `json:"comment,omitempty"`
+		Distribution string         `json:"distribution"`
+		FirstSeen    string         `json:"first_seen,omitempty"`
+		Timestamp    string         `json:"timestamp"`
+		Sighting     []MISPSighting `json:"Sighting,omitempty"`
+		Tag          []MISPTag      `json:"Tag,omitempty"`
+		ToIDS        bool           `json:"to_ids"`
+	}
+
+	// MISPEvent wraps a MISP event, as expected by the MISP import API.
+	MISPEvent struct {
+		Event MISPEventBody `json:"Event"`
+	}
+
+	// MISPEventBody is the body of a MISP event.
+	MISPEventBody struct {
+		UUID          string          `json:"uuid"`
+		Info          string          `json:"info"`
+		Date          string          `json:"date"`
+		ThreatLevelID string          `json:"threat_level_id"`
+		Analysis      string          `json:"analysis"`
+		Distribution  string          `json:"distribution"`
+		Timestamp     string          `json:"timestamp"`
+		Tag           []MISPTag       `json:"Tag,omitempty"`
+		Published     bool            `json:"published"`
+		Attribute     []MISPAttribute `json:"Attribute,omitzero"`
+	}
+
+	// MISPEventWriter streams a MISP event to a writer one domain at a time, so large exports don't need to be held in
+	// memory. Attributes shared between domains (e.g. the same URL) are only written once per event.
+	MISPEventWriter struct {
+		w       io.Writer
+		uuids   map[string]bool
+		written int
+		closed  bool
+	}
+
+	// MISPSighting records that a provider reported an attribute.
+	MISPSighting struct {
+		Type         string `json:"type"`
+		Source       string `json:"source"`
+		DateSighting string `json:"date_sighting"`
+	}
+
+	// MISPTag is a MISP tag.
+	MISPTag struct {
+		Name string `json:"name"`
+	}
+)
+
+// DomainToMISP maps a domain to MISP attributes: one domain attribute plus one url attribute per distinct reported URL.
+// Each attribute is tagged with the abuse type, classification, provider rating and confidence (see Confidence),
+// carries a sighting for every provider that reported the domain, and uses DateIdentified as its first_seen. Domains
+// classified as definitely clean are exported with to_ids disabled.
+func DomainToMISP(domain *model.Domain) []MISPAttribute {
+	name := strings.TrimSuffix(strings.ToLower(domain.Domain), ".")
+	primary := &domain.DomainSubmission
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	tags := mispTags(primary)
+
+	var sightings []MISPSighting
+	for _, submission := range submissions(domain) {
+		sightings = append(sightings, MISPSighting{
+			Type:         MISPSightingTypeSighting,
+			Source:       submission.ProviderName,
+			DateSighting: strconv.FormatInt(firstSeen(submission).Unix(), 10),
+		})
+	}
+
+	comment := primary.Comments
+	if primary.ProviderName != "" {
+		comment = strings.TrimSpace("Reported by " + primary.ProviderName + ". " + comment)
+	}
+
+	attributes := []MISPAttribute{{
+		UUID:         uuidV5(mispNamespace, "domain|"+name),
+		Type:         MISPAttributeTypeDomain,
+		Category:     MISPCategoryNetwork,
+		Value:        name,
+		Comment:      comment,
+		Distribution: MISPDistributionOrgOnly,
+		FirstSeen:    firstSeen(primary).Format(mispFirstSeenTimestampFmt),
+		Timestamp:    timestamp,
+		Sighting:     sightings,
+		Tag:          tags,
+		ToIDS:        isMalicious(primary),
+	}}
+
+	urls := make(map[string]bool, len(primary.URLs))
+
+	for _, rawURL := range primary.URLs {
+		if urls[rawURL] {
+			continue
+		}
+
+		urls[rawURL] = true
+
+		attributes = append(attributes, MISPAttribute{
+			UUID:         uuidV5(mispNamespace, "url|"+rawURL),
+			Type:         MISPAttributeTypeURL,
+			Category:     MISPCategoryNetwork,
+			Value:        rawURL,
+			Comment:      "URL reported for " + name,
+			Distribution: MISPDistributionOrgOnly,
+			FirstSeen:    firstSeen(primary).Format(mispFirstSeenTimestampFmt),
+			Timestamp:    timestamp,
+			Tag:          tags,
+			ToIDS:        isMalicious(primary),
+		})
+	}
+
+	return attributes
+}
+
+// NewMISPEvent converts domains into a single MISP event with the given description. Each attribute is only included
+// once, even if several domains map to it.
+func NewMISPEvent(info string, domains ...*model.Domain) *MISPEvent {
+	event := &MISPEvent{Event: newMISPEventBody(info)}
+	seen := make(map[string]bool)
+
+	for _, domain := range domains {
+		for _, attribute := range DomainToMISP(domain) {
+			if seen[attribute.UUID] {
+				continue
+			}
+
+			seen[attribute.UUID] = true
+			event.Event.Attribute = append(event.Event.Attribute, attribute)
+		}
+	}
+
+	return event
+}
+
+// NewMISPEventWriter starts a MISP event with the given description on w. Call Close once every domain has been
+// written.
+func NewMISPEventWriter(w io.Writer, info string) (*MISPEventWriter, error) {
+	body := newMISPEventBody(info)
+	body.Attribute = nil // omitzero leaves a nil list out, so the attributes can be streamed after the header.
+
+	header, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event header: %w", err)
+	}
+
+	// Reopen the marshaled object and add the attribute list as its last key.
+	header = bytes.TrimSuffix(header, []byte{'}'})
+
+	if _, err = fmt.Fprintf(w, `{"Event":%s,"Attribute":[`, header); err != nil {
+		return nil, fmt.Errorf("write event header: %w", err)
+	}
+
+	return &MISPEventWriter{w: w, uuids: make(map[string]bool)}, nil
+}
+
+// Close finishes the event. It doesn't close the underlying writer.
+func (ew *MISPEventWriter) Close() error {
+	if ew.closed {
+		return nil
+	}
+
+	ew.closed = true
+
+	if _, err := io.WriteString(ew.w, "]}}\n"); err != nil {
+		return fmt.Errorf("write event footer: %w", err)
+	}
+
+	return nil
+}
+
+// Write appends the MISP attributes for a domain to the event.
+func (ew *MISPEventWriter) Write(domain *model.Domain) error {
+	if ew.closed {
+		return errors.New("write to closed event")
+	}
+
+	for _, attribute := range DomainToMISP(domain) {
+		if ew.uuids[attribute.UUID] {
+			continue
+		}
+
+		ew.uuids[attribute.UUID] = true
+
+		data, err := json.Marshal(attribute)
+		if err != nil {
+			return fmt.Errorf("marshal misp attribute: %w", err)
+		}
+
+		if ew.written > 0 {
+			data = append([]byte{','}, data...)
+		}
+
+		if _, err = ew.w.Write(data); err != nil {
+			return fmt.Errorf("write misp attribute: %w", err)
+		}
+
+		ew.written++
+	}
+
+	return nil
+}
+
+// mispTags returns the tags describing a submission, in the domain-trust namespace.
+func mispTags(submission *model.DomainSubmission) []MISPTag {
+	var tags []MISPTag
+
+	for _, kv := range [][2]string{
+		{"abuse-type", submission.AbuseType},
+		{"classification", submission.Classification},
+		{"provider-rating", submission.ProviderRating},
+		{"report-type", submission.ReportType},
+		{"confidence", strconv.Itoa(Confidence(submission))},
+	} {
+		if kv[1] != "" {
+			tags = append(tags, MISPTag{Name: fmt.Sprintf("%s:%s=%q", MISPTagNamespace, kv[0], kv[1])})
+		}
+	}
+
+	return tags
+}
+
+func newMISPEventBody(info string) MISPEventBody {
+	now := time.Now().UTC()
+
+	return MISPEventBody{
+		UUID:          uuidV4(),
+		Info:          info,
+		Date:          now.Format(time.DateOnly),
+		ThreatLevelID: MISPThreatLevelMedium,
+		Analysis:      MISPAnalysisCompleted,
+		Distribution:  MISPDistributionOrgOnly,
+		Timestamp:     strconv.FormatInt(now.Unix(), 10),
+		Tag:           []MISPTag{{Name: MISPTagNamespace + ":source=\"domain-trust\""}},
+		Attribute:     []MISPAttribute{},
+	}
+}
@@ -0,0 +1,563 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+const (
+	STIXSpecVersion = "2.1"
+
+	// STIXTimestampFormat is the timestamp layout STIX 2.1 requires (UTC, millisecond precision).
+	STIXTimestampFormat = "2006-01-02T15:04:05.000Z"
+)
+
+var (
+	// stixSCONamespace is the namespace the STIX 2.1 spec mandates for deterministic SCO identifiers.
+	stixSCONamespace = parseUUID("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+	// stixSDONamespace is used to derive stable identifiers for the SDOs/SROs we generate, so re-exporting the same
+	// domain produces the same objects.
+	stixSDONamespace = parseUUID("5c6e9c4e-3f0b-4a57-9d8e-6b1f2a7c0d31")
+
+	// stixDomainPattern matches the domain names compared in a STIX pattern, e.g. [domain-name:value = 'example.com'].
+	stixDomainPattern = regexp.MustCompile(`domain-name:value\s*=\s*'((?:[^'\\]|\\.)*)'`)
+
+	// STIXProducerID is the identifier of the identity that produces exported bundles.
+	STIXProducerID = "identity--" + uuidV5(stixSDONamespace, "identity|"+ProducerName)
+)
+
+type (
+	// STIXBundle is a STIX 2.1 bundle.
+	STIXBundle struct {
+		Type    string `json:"type"`
+		ID      string `json:"id"`
+		Objects []any  `json:"objects"`
+	}
+
+	// STIXDomainName is a STIX 2.1 domain-name SCO.
+	STIXDomainName struct {
+		Type        string `json:"type"`
+		SpecVersion string `json:"spec_version"`
+		ID          string `json:"id"`
+		Value       string `json:"value"`
+	}
+
+	// STIXIdentity is a STIX 2.1 identity SDO.
+	STIXIdentity struct {
+		Type          string        `json:"type"`
+		SpecVersion   string        `json:"spec_version"`
+		ID            string        `json:"id"`
+		Created       STIXTimestamp `json:"created"`
+		Modified      STIXTimestamp `json:"modified"`
+		Name          string        `json:"name"`
+		IdentityClass string        `json:"identity_class"`
+	}
+
+	// STIXIndicator is a STIX 2.1 indicator SDO.
+	STIXIndicator struct {
+		Type           string        `json:"type"`
+		SpecVersion    string        `json:"spec_version"`
+		ID             string        `json:"id"`
+		CreatedByRef   string        `json:"created_by_ref,omitempty"`
+		Created        STIXTimestamp `json:"created"`
+		Modified       STIXTimestamp `json:"modified"`
+		Name           string        `json:"name,omitempty"`
+		Description    string        `json:"description,omitempty"`
+		IndicatorTypes []string      `json:"indicator_types,omitempty"`
+		Pattern        string        `json:"pattern"`
+		PatternType    string        `json:"pattern_type"`
+		ValidFrom      STIXTimestamp `json:"valid_from"`
+		Labels         []string      `json:"labels,omitempty"`
+		Confidence     int           `json:"confidence"`
+	}
+
+	// STIXRelationship is a STIX 2.1 relationship SRO.
+	STIXRelationship struct {
+		Type             string        `json:"type"`
+		SpecVersion      string        `json:"spec_version"`
+		ID               string        `json:"id"`
+		CreatedByRef     string        `json:"created_by_ref,omitempty"`
+		Created          STIXTimestamp `json:"created"`
+		Modified         STIXTimestamp `json:"modified"`
+		RelationshipType string        `json:"relationship_type"`
+		SourceRef        string        `json:"source_ref"`
+		TargetRef        string        `json:"target_ref"`
+	}
+
+	// STIXSighting is a STIX 2.1 sighting SRO.
+	STIXSighting struct {
+		Type             string        `json:"type"`
+		SpecVersion      string        `json:"spec_version"`
+		ID               string        `json:"id"`
+		CreatedByRef     string        `json:"created_by_ref,omitempty"`
+		Created          STIXTimestamp `json:"created"`
+		Modified         STIXTimestamp `json:"modified"`
+		Description      string        `json:"description,omitempty"`
+		FirstSeen        STIXTimestamp `json:"first_seen"`
+		LastSeen         STIXTimestamp `json:"last_seen"`
+		Count            int           `json:"count"`
+		SightingOfRef    string        `json:"sighting_of_ref"`
+		WhereSightedRefs []string      `json:"where_sighted_refs,omitempty"`
+		Labels           []string      `json:"labels,omitempty"`
+		Confidence       int           `json:"confidence"`
+	}
+
+	// stixObject holds the fields of any STIX object that are needed to import domains.
+	stixObject struct {
+		Type           string        `json:"type"`
+		Value          string        `json:"value"`
+		Pattern        string        `json:"pattern"`
+		PatternType    string        `json:"pattern_type"`
+		Description    string        `json:"description"`
+		ValidFrom      STIXTimestamp `json:"valid_from"`
+		IndicatorTypes []string      `json:"indicator_types"`
+		Labels         []string      `json:"labels"`
+	}
+
+	// STIXTimestamp is a time.Time that marshals using the STIX 2.1 timestamp format.
+	STIXTimestamp time.Time
+
+	// STIXURL is a STIX 2.1 url SCO.
+	STIXURL struct {
+		Type        string `json:"type"`
+		SpecVersion string `json:"spec_version"`
+		ID          string `json:"id"`
+		Value       string `json:"value"`
+	}
+
+	// STIXBundleWriter streams a STIX bundle to a writer one domain at a time, so large exports don't need to be held in
+	// memory. Provider identities and url objects are only written once per bundle.
+	STIXBundleWriter struct {
+		w       io.Writer
+		shared  map[string]bool
+		written int
+		closed  bool
+	}
+
+	// stixSighting aggregates a provider's submissions of a domain into a single sighting.
+	stixSighting struct {
+		// latest is the provider's most recent submission, which the sighting's description, labels and confidence
+		// are taken from.
+		latest    *model.DomainSubmission
+		provider  string
+		firstSeen time.Time
+		lastSeen  time.Time
+		count     int
+	}
+)
+
+// NewSTIXBundle converts domains into a single STIX 2.1 bundle.
+func NewSTIXBundle(domains ...*model.Domain) *STIXBundle {
+	bundle := &STIXBundle{
+		Type:    "bundle",
+		ID:      "bundle--" + uuidV4(),
+		Objects: []any{producerIdentity()},
+	}
+
+	seen := map[string]bool{STIXProducerID: true}
+
+	for _, domain := range domains {
+		for _, object := range DomainToSTIX(domain) {
+			if id, ok := sharedID(object); ok {
+				if seen[id] {
+					continue
+				}
+
+				seen[id] = true
+			}
+
+			bundle.Objects = append(bundle.Objects, object)
+		}
+	}
+
+	return bundle
+}
+
+// STIXToDomains reads a STIX 2.1 bundle and converts it into domain submissions. Every domain referenced by a STIX
+// pattern in an indicator becomes a submission, with the indicator's labels mapped back to the abuse type,
+// classification and report type (a "benign" indicator type is treated as definitely-clean), its valid_from used as the
+// date identified, and its description as the comments. Any remaining domain-name objects become bare submissions.
+// Each domain is only returned once.
+func STIXToDomains(r io.Reader) ([]*model.DomainSubmission, error) {
+	var bundle struct {
+		Type    string       `json:"type"`
+		Objects []stixObject `json:"objects"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("decode stix bundle: %w", err)
+	}
+
+	if bundle.Type != "bundle" {
+		return nil, fmt.Errorf("expected a stix bundle, got %q", bundle.Type)
+	}
+
+	var domains []*model.DomainSubmission
+	seen := make(map[string]bool)
+
+	add := func(submission *model.DomainSubmission) {
+		submission.Domain = strings.TrimSuffix(strings.ToLower(submission.Domain), ".")
+		if submission.Domain == "" || seen[submission.Domain] {
+			return
+		}
+
+		seen[submission.Domain] = true
+		domains = append(domains, submission)
+	}
+
+	for _, object := range bundle.Objects {
+		if object.Type != "indicator" || (object.PatternType != "" && object.PatternType != "stix") {
+			continue
+		}
+
+		for _, match := range stixDomainPattern.FindAllStringSubmatch(object.Pattern, -1) {
+			submission := &model.DomainSubmission{
+				Domain:         stixUnescape(match[1]),
+				Comments:       object.Description,
+				DateIdentified: time.Time(object.ValidFrom),
+			}
+
+			for _, label := range object.Labels {
+				applyLabel(submission, strings.ToLower(label))
+			}
+
+			if submission.Classification == "" && slices.Contains(object.IndicatorTypes, "benign") {
+				submission.Classification = model.DomainClassificationDefinitelyClean
+			}
+
+			add(submission)
+		}
+	}
+
+	for _, object := range bundle.Objects {
+		if object.Type == "domain-name" {
+			add(&model.DomainSubmission{Domain: object.Value})
+		}
+	}
+
+	return domains, nil
+}
+
+// NewSTIXBundleWriter starts a STIX bundle on w. Call Close once every domain has been written.
+func NewSTIXBundleWriter(w io.Writer) (*STIXBundleWriter, error) {
+	if _, err := fmt.Fprintf(w, `{"type":"bundle","id":"bundle--%s","objects":[`, uuidV4()); err != nil {
+		return nil, fmt.Errorf("write bundle header: %w", err)
+	}
+
+	bw := &STIXBundleWriter{
+		w:      w,
+		shared: map[string]bool{STIXProducerID: true},
+	}
+
+	if err := bw.writeObject(producerIdentity()); err != nil {
+		return nil, err
+	}
+
+	return bw, nil
+}
+
+// DomainToSTIX maps a domain to STIX 2.1 objects: a domain-name SCO, an indicator for the domain, url SCOs (related to
+// the indicator) for each reported URL, and an identity plus sighting for every provider that reported it.
+//
+// The indicator's labels hold the abuse type, classification and report type, and its confidence is derived from the
+// classification and provider rating (see Confidence). DateIdentified becomes the indicator's valid_from. A provider's
+// submissions are combined into one sighting, spanning the earliest to the latest DateIdentified, with its count set
+// to the number of submissions and its description, labels and confidence taken from the latest one.
+//
+// Identities and url objects may be shared between domains; NewSTIXBundle and STIXBundleWriter only include them once.
+func DomainToSTIX(domain *model.Domain) []any {
+	name := strings.TrimSuffix(strings.ToLower(domain.Domain), ".")
+	primary := &domain.DomainSubmission
+	now := STIXTimestamp(time.Now().UTC())
+	validFrom := STIXTimestamp(firstSeen(primary))
+
+	domainName := &STIXDomainName{
+		Type:        "domain-name",
+		SpecVersion: STIXSpecVersion,
+		ID:          "domain-name--" + scoID(name),
+		Value:       name,
+	}
+
+	indicatorType := "malicious-activity"
+	if !isMalicious(primary) {
+		indicatorType = "benign"
+	}
+
+	indicator := &STIXIndicator{
+		Type:           "indicator",
+		SpecVersion:    STIXSpecVersion,
+		ID:             "indicator--" + uuidV5(stixSDONamespace, "indicator|"+name),
+		CreatedByRef:   STIXProducerID,
+		Created:        validFrom,
+		Modified:       now,
+		Name:           name,
+		Description:    primary.Comments,
+		IndicatorTypes: []string{indicatorType},
+		Pattern:        fmt.Sprintf("[domain-name:value = '%s']", stixEscape(name)),
+		PatternType:    "stix",
+		ValidFrom:      validFrom,
+		Labels:         labels(primary),
+		Confidence:     Confidence(primary),
+	}
+
+	objects := []any{domainName, indicator}
+	urls := make(map[string]bool, len(primary.URLs))
+
+	for _, rawURL := range primary.URLs {
+		if urls[rawURL] {
+			continue
+		}
+
+		urls[rawURL] = true
+
+		url := &STIXURL{
+			Type:        "url",
+			SpecVersion: STIXSpecVersion,
+			ID:          "url--" + scoID(rawURL),
+			Value:       rawURL,
+		}
+
+		objects = append(objects, url, &STIXRelationship{
+			Type:             "relationship",
+			SpecVersion:      STIXSpecVersion,
+			ID:               "relationship--" + uuidV5(stixSDONamespace, "relationship|"+indicator.ID+"|"+url.ID),
+			CreatedByRef:     STIXProducerID,
+			Created:          validFrom,
+			Modified:         now,
+			RelationshipType: "related-to",
+			SourceRef:        indicator.ID,
+			TargetRef:        url.ID,
+		})
+	}
+
+	for _, sighting := range sightings(domain) {
+		identity := &STIXIdentity{
+			Type:          "identity",
+			SpecVersion:   STIXSpecVersion,
+			ID:            "identity--" + uuidV5(stixSDONamespace, "identity|"+sighting.provider),
+			Created:       now,
+			Modified:      now,
+			Name:          sighting.provider,
+			IdentityClass: "organization",
+		}
+
+		objects = append(objects, identity, &STIXSighting{
+			Type:             "sighting",
+			SpecVersion:      STIXSpecVersion,
+			ID:               "sighting--" + uuidV5(stixSDONamespace, "sighting|"+name+"|"+sighting.provider),
+			CreatedByRef:     STIXProducerID,
+			Created:          STIXTimestamp(sighting.firstSeen),
+			Modified:         now,
+			Description:      sighting.latest.Comments,
+			FirstSeen:        STIXTimestamp(sighting.firstSeen),
+			LastSeen:         STIXTimestamp(sighting.lastSeen),
+			Count:            sighting.count,
+			SightingOfRef:    indicator.ID,
+			WhereSightedRefs: []string{identity.ID},
+			Labels:           labels(sighting.latest),
+			Confidence:       Confidence(sighting.latest),
+		})
+	}
+
+	return objects
+}
+
+// Close finishes the bundle. It doesn't close the underlying writer.
+func (bw *STIXBundleWriter) Close() error {
+	if bw.closed {
+		return nil
+	}
+
+	bw.closed = true
+
+	if _, err := io.WriteString(bw.w, "]}\n"); err != nil {
+		return fmt.Errorf("write bundle footer: %w", err)
+	}
+
+	return nil
+}
+
+// Write appends the STIX objects for a domain to the bundle.
+func (bw *STIXBundleWriter) Write(domain *model.Domain) error {
+	if bw.closed {
+		return errors.New("write to closed bundle")
+	}
+
+	for _, object := range DomainToSTIX(domain) {
+		if id, ok := sharedID(object); ok {
+			if bw.shared[id] {
+				continue
+			}
+
+			bw.shared[id] = true
+		}
+
+		if err := bw.writeObject(object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bw *STIXBundleWriter) writeObject(object any) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("marshal stix object: %w", err)
+	}
+
+	if bw.written > 0 {
+		data = append([]byte{','}, data...)
+	}
+
+	if _, err = bw.w.Write(data); err != nil {
+		return fmt.Errorf("write stix object: %w", err)
+	}
+
+	bw.written++
+
+	return nil
+}
+
+// MarshalJSON formats the timestamp as required by STIX 2.1.
+func (t STIXTimestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + time.Time(t).UTC().Format(STIXTimestampFormat) + `"`), nil
+}
+
+// UnmarshalJSON parses an RFC 3339 timestamp.
+func (t *STIXTimestamp) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("unmarshal stix timestamp: %w", err)
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("parse stix timestamp: %w", err)
+	}
+
+	*t = STIXTimestamp(parsed)
+
+	return nil
+}
+
+// applyLabel sets the submission field that a STIX label corresponds to, if any.
+func applyLabel(submission *model.DomainSubmission, label string) {
+	switch label {
+	case model.DomainAbuseTypeBotnets, model.DomainAbuseTypeMalware, model.DomainAbuseTypePharming,
+		model.DomainAbuseTypePhishing, model.DomainAbuseTypeSpam:
+		submission.AbuseType = label
+	case model.DomainClassificationDefinitelyMalicious, model.DomainClassificationProbablyMalicious,
+		model.DomainClassificationPossiblyMalicious, model.DomainClassificationDefinitelyClean:
+		submission.Classification = label
+	case model.DomainReportTypeBrandSpoof, model.DomainReportTypeFraud:
+		submission.ReportType = label
+	}
+}
+
+// labels returns the non-empty, de-duplicated descriptive values of a submission.
+func labels(submission *model.DomainSubmission) []string {
+	var out []string
+
+	for _, label := range []string{submission.AbuseType, submission.Classification, submission.ReportType} {
+		if label != "" && !slices.Contains(out, label) {
+			out = append(out, label)
+		}
+	}
+
+	return out
+}
+
+// sharedID returns the ID of an object that several domains can map to (an identity or url), so it can be written
+// once per bundle.
+func sharedID(object any) (string, bool) {
+	switch object := object.(type) {
+	case *STIXIdentity:
+		return object.ID, true
+	case *STIXURL:
+		return object.ID, true
+	default:
+		return "", false
+	}
+}
+
+// sightings groups a domain's submissions by provider, in the order each provider first appears.
+func sightings(domain *model.Domain) []*stixSighting {
+	var out []*stixSighting
+
+	byProvider := make(map[string]*stixSighting)
+
+	for _, submission := range submissions(domain) {
+		provider := submission.ProviderName
+		if provider == "" {
+			provider = "Unknown provider"
+		}
+
+		seen := firstSeen(submission)
+
+		sighting, ok := byProvider[provider]
+		if !ok {
+			sighting = &stixSighting{latest: submission, provider: provider, firstSeen: seen, lastSeen: seen}
+			byProvider[provider] = sighting
+			out = append(out, sighting)
+		}
+
+		sighting.count++
+
+		if seen.Before(sighting.firstSeen) {
+			sighting.firstSeen = seen
+		}
+
+		if seen.After(sighting.lastSeen) {
+			sighting.lastSeen = seen
+			sighting.latest = submission
+		}
+	}
+
+	return out
+}
+
+func producerIdentity() *STIXIdentity {
+	now := STIXTimestamp(time.Now().UTC())
+
+	return &STIXIdentity{
+		Type:          "identity",
+		SpecVersion:   STIXSpecVersion,
+		ID:            STIXProducerID,
+		Created:       now,
+		Modified:      now,
+		Name:          ProducerName,
+		IdentityClass: "organization",
+	}
+}
+
+// scoID derives a STIX 2.1 deterministic identifier for an SCO whose only ID contributing property is "value". The
+// property is serialized as RFC 8785 (JCS) requires, so without escaping HTML characters such as '&'.
+func scoID(value string) string {
+	var data strings.Builder
+
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(map[string]string{"value": value})
+
+	return uuidV5(stixSCONamespace, strings.TrimSuffix(data.String(), "\n"))
+}
+
+// stixUnescape reverses stixEscape.
+func stixUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`).Replace(s)
+}
+
+// stixEscape escapes a string for use inside a quoted STIX pattern literal.
+func stixEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
@@ -0,0 +1,20 @@
+package convert
+
+import "testing"
+
+func TestSCOID(t *testing.T) {
+	// Expected IDs are UUIDv5s of the JCS-serialized {"value":...} object in the STIX SCO namespace.
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "example.com", want: "bedb4899-d24b-5401-bc86-8f6b4cc18ec7"},
+		{value: "https://example.com/login?user=a&next=<b>", want: "293b0957-cb21-5e49-ae7b-c0d5a537376e"},
+	}
+
+	for _, tt := range tests {
+		if got := scoID(tt.value); got != tt.want {
+			t.Errorf("scoID(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
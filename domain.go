@@ -3,12 +3,24 @@ package client
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
+	"time"
 
+	"github.com/globalcyberalliance/domain-trust-go/v2/domainutil"
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
 )
 
 func (c *Client) CreateDomains(ctx context.Context, domains ...*model.DomainSubmission) ([]*model.DomainError, error) {
+	var domainErrs []*model.DomainError
+
+	if c.normalizeDomains {
+		domains, domainErrs = normalizeDomains(domains)
+		if len(domains) == 0 {
+			return domainErrs, nil
+		}
+	}
+
 	body, err := c.marshal(map[string][]*model.DomainSubmission{"domains": domains})
 	if err != nil {
 		return nil, fmt.Errorf("marshal domains: %w", err)
@@ -18,21 +30,36 @@ func (c *Client) CreateDomains(ctx context.Context, domains ...*model.DomainSubm
 		Errors []*model.DomainError `json:"errors"`
 	}
 
-	if _, err = c.POST(ctx, "domains", body, &response); err != nil {
+	if _, err = c.POST(withOperation(ctx, "CreateDomains"), "domains", body, &response); err != nil {
 		return nil, fmt.Errorf("create domains: %w", err)
 	}
 
-	return response.Errors, nil
+	return append(domainErrs, response.Errors...), nil
+}
+
+func (c *Client) DeleteDomain(ctx context.Context, domainID string) error {
+	if _, err := c.DELETE(withOperation(ctx, "DeleteDomain"), "domains/"+domainID, nil); err != nil {
+		return fmt.Errorf("delete domain: %w", err)
+	}
+
+	return nil
+}
+
+// Domains iterates over every domain matching the filter, fetching pages as needed.
+func (c *Client) Domains(ctx context.Context, filter *model.DomainFilter) iter.Seq2[*model.Domain, error] {
+	domainIterator, _ := c.FindDomainsPaged(ctx, filter)
+
+	return domainIterator.All()
 }
 
 func (c *Client) FindDomains(ctx context.Context, filter *model.DomainFilter) ([]*model.Domain, error) {
-	query := structToQueryParams(filter)
+	query := domainFilterQuery(filter)
 
 	var response struct {
 		Domains []*model.Domain `json:"domains"`
 	}
 
-	if _, err := c.GET(ctx, "domains?"+query, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindDomains"), "domains?"+query, &response); err != nil {
 		return nil, fmt.Errorf("find domains: %w", err)
 	}
 
@@ -41,7 +68,7 @@ func (c *Client) FindDomains(ctx context.Context, filter *model.DomainFilter) ([
 
 func (c *Client) FindDomainsPaged(ctx context.Context, filter *model.DomainFilter) (*Iterator[*model.Domain], error) {
 	fetch := func(ctx context.Context, pageToken string) ([]*model.Domain, string, error) {
-		q := structToQueryParams(filter)
+		q := domainFilterQuery(filter)
 		if pageToken != "" {
 			q += "&pageToken=" + url.QueryEscape(pageToken)
 		}
@@ -51,19 +78,103 @@ func (c *Client) FindDomainsPaged(ctx context.Context, filter *model.DomainFilte
 			NextPageToken string          `json:"nextPageToken"`
 		}
 
-		if _, err := c.GET(ctx, "domains?"+q, &resp); err != nil {
+		if _, err := c.GET(withOperation(ctx, "FindDomainsPaged"), "domains?"+q, &resp); err != nil {
 			return nil, "", fmt.Errorf("find domains: %w", err)
 		}
 
 		return resp.Domains, resp.NextPageToken, nil
 	}
 
-	// Initialize iterator (fetch first page lazily).
-	return &Iterator[*model.Domain]{
-		ctx:       ctx,
-		fetchPage: fetch,
-		index:     0,
-		nextToken: "",
-		page:      nil,
-	}, nil
+	return newIterator(ctx, fetch), nil
+}
+
+func (c *Client) FindDomainByID(ctx context.Context, id string) (*model.Domain, error) {
+	var response struct {
+		Domain *model.Domain `json:"domain"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindDomainByID"), "domains/"+id, &response); err != nil {
+		return nil, fmt.Errorf("find domain: %w", err)
+	}
+
+	return response.Domain, nil
+}
+
+// UpdateDomain updates the fields of a domain that are set in update (a non-empty OrganizationID, and any non-nil
+// provider field), leaving the rest unchanged.
+func (c *Client) UpdateDomain(ctx context.Context, id string, update *model.DomainUpdate) (*model.Domain, error) {
+	body, err := c.marshal(map[string]map[string]string{"domain": domainUpdateFields(update)})
+	if err != nil {
+		return nil, fmt.Errorf("marshal update: %w", err)
+	}
+
+	var response struct {
+		Domain *model.Domain `json:"domain"`
+	}
+
+	if _, err = c.PATCH(withOperation(ctx, "UpdateDomain"), "domains/"+id, body, &response); err != nil {
+		return nil, fmt.Errorf("update domain: %w", err)
+	}
+
+	return response.Domain, nil
+}
+
+// domainUpdateFields returns the fields set in update. The generated model would send null for every unset field,
+// which the API treats as clearing it.
+func domainUpdateFields(update *model.DomainUpdate) map[string]string {
+	fields := make(map[string]string)
+	if update == nil {
+		return fields
+	}
+
+	if update.OrganizationID != "" {
+		fields["organizationID"] = update.OrganizationID
+	}
+
+	if update.ProviderName != nil {
+		fields["providerName"] = *update.ProviderName
+	}
+
+	if update.ProviderRating != nil {
+		fields["providerRating"] = *update.ProviderRating
+	}
+
+	if update.ProviderRole != nil {
+		fields["providerRole"] = *update.ProviderRole
+	}
+
+	return fields
+}
+
+// domainFilterQuery encodes filter as query parameters.
+func domainFilterQuery(filter *model.DomainFilter) string {
+	query := structToQueryParams(filter)
+
+	// The generated filter's tag for dateIdentifiedBefore is malformed, so its query tag can't be read.
+	if filter != nil && !filter.DateIdentifiedBefore.IsZero() {
+		query += "&dateIdentifiedBefore=" + url.QueryEscape(filter.DateIdentifiedBefore.Format(time.RFC3339))
+	}
+
+	return query
+}
+
+// normalizeDomains returns normalized copies of the submissions (leaving the originals untouched), along with a domain
+// error for each one that couldn't be normalized.
+func normalizeDomains(domains []*model.DomainSubmission) ([]*model.DomainSubmission, []*model.DomainError) {
+	var domainErrs []*model.DomainError
+
+	normalized := make([]*model.DomainSubmission, 0, len(domains))
+
+	for _, domain := range domains {
+		submission := *domain
+
+		if err := domainutil.Fill(&submission); err != nil {
+			domainErrs = append(domainErrs, &model.DomainError{Domain: domain.Domain, Error: err.Error()})
+			continue
+		}
+
+		normalized = append(normalized, &submission)
+	}
+
+	return normalized, domainErrs
 }
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+func TestUpdateDomainBody(t *testing.T) {
+	rating := "low-confidence"
+	name := ""
+
+	tests := []struct {
+		name   string
+		update *model.DomainUpdate
+		want   string
+	}{
+		{name: "single field", update: &model.DomainUpdate{ProviderRating: &rating}, want: `{"domain":{"providerRating":"low-confidence"}}`},
+		{name: "organization", update: &model.DomainUpdate{OrganizationID: "org-1"}, want: `{"domain":{"organizationID":"org-1"}}`},
+		{name: "cleared field", update: &model.DomainUpdate{ProviderName: &name}, want: `{"domain":{"providerName":""}}`},
+		{name: "empty", update: &model.DomainUpdate{}, want: `{"domain":{}}`},
+	}
+
+	for _, tt := range tests {
+		var body string
+
+		c := New("key", WithContentType(ContentTypeJSON), WithMiddleware(func(Handler) Handler {
+			return func(_ context.Context, req *Request) (*Response, error) {
+				body = string(req.Body)
+				return &Response{Header: http.Header{}, Body: []byte(`{}`), StatusCode: http.StatusOK}, nil
+			}
+		}))
+
+		if _, err := c.UpdateDomain(context.Background(), "id", tt.update); err != nil {
+			t.Fatalf("%s: UpdateDomain() error = %v", tt.name, err)
+		}
+
+		if body != tt.want {
+			t.Errorf("%s: UpdateDomain() sent %s, want %s", tt.name, body, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package domainutil normalizes domain names and splits them into their parts (subdomain, second-level domain and
+// public suffix) using an embedded snapshot of the Public Suffix List.
+package domainutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+var (
+	ErrInvalidDomain = errors.New("invalid domain")
+	ErrPublicSuffix  = errors.New("domain is a public suffix")
+
+	// profile maps names for lookup (lower-casing and converting IDNs to punycode), but allows underscores, which are
+	// common in abusive hostnames.
+	profile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+)
+
+// Parts holds a domain split into its components, e.g. for "www.example.co.uk": Subdomain "www", SLD "example",
+// TLD "co.uk" and RootDomain "example.co.uk".
+type Parts struct {
+	Domain     string
+	RootDomain string
+	SLD        string
+	Subdomain  string
+	TLD        string
+
+	// ICANN reports whether the TLD is an ICANN suffix, rather than a privately registered one (e.g. "github.io").
+	ICANN bool
+}
+
+// Fill normalizes the submission's domain and sets its SLD, TLD, RootDomain and Subdomain from it.
+func Fill(submission *model.DomainSubmission) error {
+	parts, err := Split(submission.Domain)
+	if err != nil {
+		return err
+	}
+
+	submission.Domain = parts.Domain
+	submission.RootDomain = parts.RootDomain
+	submission.SLD = parts.SLD
+	submission.Subdomain = parts.Subdomain
+	submission.TLD = parts.TLD
+
+	return nil
+}
+
+// Normalize converts input into a canonical domain name: the scheme, user info, port, path, query and fragment of a
+// pasted URL are stripped, the name is lower-cased, trailing dots are removed, and internationalized names are
+// converted to their ASCII (punycode) form. IP addresses and malformed names return ErrInvalidDomain.
+func Normalize(input string) (string, error) {
+	host := strings.TrimSpace(input)
+
+	if strings.Contains(host, "://") {
+		parsed, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q: %w", ErrInvalidDomain, input, err)
+		}
+
+		host = parsed.Host
+	} else {
+		host, _, _ = strings.Cut(host, "/")
+		host, _, _ = strings.Cut(host, "?")
+		host, _, _ = strings.Cut(host, "#")
+
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	host = strings.TrimRight(host, ".")
+
+	if host == "" {
+		return "", fmt.Errorf("%w: %q is empty", ErrInvalidDomain, input)
+	}
+
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return "", fmt.Errorf("%w: %q is an IP address", ErrInvalidDomain, input)
+	}
+
+	ascii, err := profile.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidDomain, input, err)
+	}
+
+	if len(ascii) > maxDomainLength {
+		return "", fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidDomain, input, maxDomainLength)
+	}
+
+	for label := range strings.SplitSeq(ascii, ".") {
+		if label == "" || len(label) > maxLabelLength {
+			return "", fmt.Errorf("%w: %q has an empty or overlong label", ErrInvalidDomain, input)
+		}
+
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") || strings.ContainsAny(label, " \t*!\"$&'()+,;<=>[\\]^`{|}~%") {
+			return "", fmt.Errorf("%w: %q has an invalid label %q", ErrInvalidDomain, input, label)
+		}
+	}
+
+	return ascii, nil
+}
+
+// Split normalizes input (see Normalize) and splits it into its parts using the Public Suffix List. Names that are
+// themselves a public suffix (e.g. "co.uk") return ErrPublicSuffix.
+func Split(input string) (*Parts, error) {
+	domain, err := Normalize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, icann := PublicSuffix(domain)
+	if suffix == domain {
+		return nil, fmt.Errorf("%w: %q", ErrPublicSuffix, domain)
+	}
+
+	rest := strings.TrimSuffix(domain, "."+suffix)
+
+	parts := &Parts{
+		Domain: domain,
+		ICANN:  icann,
+		TLD:    suffix,
+	}
+
+	if i := strings.LastIndex(rest, "."); i >= 0 {
+		parts.Subdomain = rest[:i]
+		parts.SLD = rest[i+1:]
+	} else {
+		parts.SLD = rest
+	}
+
+	parts.RootDomain = parts.SLD + "." + suffix
+
+	return parts, nil
+}
+
+// ToUnicode converts a normalized (ASCII) domain back to its Unicode form for display.
+func ToUnicode(domain string) (string, error) {
+	unicode, err := profile.ToUnicode(domain)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidDomain, domain, err)
+	}
+
+	return unicode, nil
+}
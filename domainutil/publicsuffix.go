@@ -0,0 +1,125 @@
+package domainutil
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+const (
+	ruleExact uint8 = 1 << iota
+	ruleWildcard
+	ruleException
+)
+
+// PublicSuffixListVersion identifies the embedded Public Suffix List snapshot (the upstream commit it was taken from).
+const PublicSuffixListVersion = "3955e3ec29b9 (2026-09-08)"
+
+// ruleSet holds the kinds of rule (ruleExact, ruleWildcard and ruleException) listed for a name, separately for the
+// list's ICANN and private sections, since the same rule can appear in both.
+type ruleSet struct {
+	icann   uint8
+	private uint8
+}
+
+var (
+	//go:embed public_suffix_list.dat
+	publicSuffixList string
+
+	rules     map[string]ruleSet
+	rulesOnce sync.Once
+)
+
+// PublicSuffix returns the public suffix of an already normalized (lower-case, ASCII) domain, e.g. "co.uk" for
+// "www.example.co.uk", and whether it's an ICANN suffix rather than a privately registered one. Domains that match no
+// rule use their last label as the suffix (the list's implicit "*" rule), which isn't treated as ICANN.
+func PublicSuffix(domain string) (string, bool) {
+	rulesOnce.Do(func() {
+		rules = parseRules(publicSuffixList)
+	})
+
+	return matchSuffix(rules, domain)
+}
+
+// has reports whether the set holds a rule of the given kind, and whether it's listed in the ICANN section (which
+// takes precedence when a rule is listed in both).
+func (r ruleSet) has(kind uint8) (bool, bool) {
+	return (r.icann|r.private)&kind != 0, r.icann&kind != 0
+}
+
+// matchSuffix returns the public suffix of domain under the known rules, and whether it's an ICANN suffix.
+func matchSuffix(known map[string]ruleSet, domain string) (string, bool) {
+	labels := strings.Split(domain, ".")
+
+	for i := range labels {
+		candidate := strings.Join(labels[i:], ".")
+		parent := strings.Join(labels[i+1:], ".")
+
+		if ok, icann := known[candidate].has(ruleException); ok {
+			return parent, icann
+		}
+
+		if ok, icann := known[candidate].has(ruleExact); ok {
+			return candidate, icann
+		}
+
+		if i+1 < len(labels) {
+			if ok, icann := known[parent].has(ruleWildcard); ok {
+				return candidate, icann
+			}
+		}
+	}
+
+	return labels[len(labels)-1], false
+}
+
+// parseRules parses a Public Suffix List, converting its rules to their ASCII (punycode) form so they can be matched
+// against normalized domains.
+func parseRules(list string) map[string]ruleSet {
+	parsed := make(map[string]ruleSet)
+	private := false
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "// ===BEGIN PRIVATE DOMAINS==="):
+			private = true
+			continue
+		case line == "" || strings.HasPrefix(line, "//"):
+			continue
+		}
+
+		// Rules end at the first whitespace.
+		line = strings.Fields(line)[0]
+
+		var kind uint8
+
+		switch {
+		case strings.HasPrefix(line, "!"):
+			line, kind = line[1:], ruleException
+		case strings.HasPrefix(line, "*."):
+			line, kind = line[2:], ruleWildcard
+		default:
+			kind = ruleExact
+		}
+
+		ascii, err := profile.ToASCII(line)
+		if err != nil {
+			continue
+		}
+
+		set := parsed[ascii]
+		if private {
+			set.private |= kind
+		} else {
+			set.icann |= kind
+		}
+
+		parsed[ascii] = set
+	}
+
+	return parsed
+}
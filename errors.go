@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Sentinel errors matching common API failure classes. Use errors.Is on an error returned by the client to branch on
+// them, or errors.As with *APIError to access the full problem details.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServerError  = errors.New("server error")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+type (
+	// APIError represents an error response (problem document) returned by the API.
+	APIError struct {
+		Body       []byte
+		Detail     string
+		Errors     []ErrorDetail
+		RequestID  string
+		StatusCode int
+		Title      string
+	}
+
+	// ErrorDetail describes a single problem with a request, usually tied to one field.
+	ErrorDetail struct {
+		Location string `json:"location,omitempty"`
+		Message  string `json:"message"`
+	}
+)
+
+func newAPIError(statusCode int, header http.Header, body []byte, problem *GenericResponse) *APIError {
+	apiErr := &APIError{
+		Body:       body,
+		RequestID:  header.Get("X-Request-Id"),
+		StatusCode: statusCode,
+	}
+
+	if problem != nil {
+		apiErr.Detail = problem.Detail
+		apiErr.Errors = problem.Errors
+		apiErr.Title = problem.Title
+
+		if problem.Status != 0 {
+			apiErr.StatusCode = problem.Status
+		}
+	}
+
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(apiErr.StatusCode)
+	}
+
+	return apiErr
+}
+
+// Error returns the problem detail, HTTP status and any per-field messages as a single string.
+func (e *APIError) Error() string {
+	return GenericResponse{
+		Title:  e.Title,
+		Status: e.StatusCode,
+		Detail: e.Detail,
+		Errors: e.Errors,
+	}.ToErrorString()
+}
+
+// Is reports whether the error matches one of the package's sentinel errors, based on its HTTP status code.
+func (e *APIError) Is(target error) bool {
+	switch {
+	case target == ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case target == ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case target == ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case target == ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case target == ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case target == ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	case target == ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	}
+
+	return false
+}
@@ -8,18 +8,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"net/http"
 	"net/netip"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/klauspost/compress/zstd"
-	"github.com/moul/http2curl"
-	"github.com/spf13/cast"
 )
 
 const (
@@ -31,12 +32,10 @@ const (
 
 type (
 	GenericResponse struct {
-		Title  string `json:"title"`
-		Status int    `json:"status"`
-		Detail string `json:"detail"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+		Title  string        `json:"title"`
+		Status int           `json:"status"`
+		Detail string        `json:"detail"`
+		Errors []ErrorDetail `json:"errors"`
 	}
 )
 
@@ -79,7 +78,84 @@ func (c *Client) POST(ctx context.Context, endpoint string, body []byte, obj any
 }
 
 func (c *Client) makeRequest(ctx context.Context, endpoint string, method string, requestBody []byte, object any) ([]byte, error) {
-	endpointURL := fmt.Sprintf("%s/%s", EndpointURL, endpoint)
+	attempts := c.retryPolicy.attempts(method)
+	ctx = context.WithValue(ctx, retryStateKey{}, &retryState{maxAttempts: attempts})
+
+	req := &Request{
+		Body:      requestBody,
+		Endpoint:  endpoint,
+		Header:    make(http.Header),
+		Method:    method,
+		Operation: operation(ctx),
+	}
+
+	// Let the API recognize retries of the same write. The key is set once, so every attempt carries it, and a key
+	// from the caller is kept across its own retries too.
+	if isMutating(method) {
+		key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+		if key == "" && attempts > 1 {
+			key = newUUID()
+		}
+
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+	}
+
+	handler := Handler(c.send)
+	for _, middleware := range slices.Backward(c.middlewares) {
+		handler = middleware(handler)
+	}
+
+	res, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		var problem *GenericResponse
+
+		if len(res.Body) > 0 {
+			resp := GenericResponse{}
+
+			switch res.Header.Get("Content-Type") {
+			case ContentTypeCBOR, "application/problem+cbor":
+				err = cbor.Unmarshal(res.Body, &resp)
+			case ContentTypeJSON, "application/problem+json":
+				err = json.Unmarshal(res.Body, &resp)
+			default:
+				err = errors.New("unknown content type")
+			}
+
+			if err == nil {
+				problem = &resp
+			}
+		}
+
+		// Return the body as it may contain a useful error message.
+		return res.Body, newAPIError(res.StatusCode, res.Header, res.Body, problem)
+	}
+
+	if len(res.Body) > 0 && object != nil {
+		switch res.Header.Get("Content-Type") {
+		case ContentTypeCBOR:
+			err = cbor.Unmarshal(res.Body, object)
+		case ContentTypeJSON:
+			err = json.Unmarshal(res.Body, object)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("request succeeded, couldn't unmarshal into object: %w", err)
+		}
+	}
+
+	return res.Body, nil
+}
+
+// send is the innermost Handler: it compresses the body, sends the request (with retries) and decompresses the
+// response body. Headers already set on req (e.g. by a middleware) take precedence over the client's defaults.
+func (c *Client) send(ctx context.Context, req *Request) (*Response, error) {
+	endpointURL := fmt.Sprintf("%s/%s", c.baseURL, req.Endpoint)
+	requestBody := req.Body
 
 	if len(requestBody) > 0 {
 		var compressedBody bytes.Buffer
@@ -101,30 +177,61 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, method string
 		requestBody = compressedBody.Bytes()
 	}
 
-	req, err := retryablehttp.NewRequestWithContext(ctx, method, endpointURL, bytes.NewBuffer(requestBody))
+	httpReq, err := retryablehttp.NewRequestWithContext(ctx, req.Method, endpointURL, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("new request: %w", err)
 	}
 
-	if c.apiKey != "" {
-		req.Header.Add("Authorization", "Bearer "+c.apiKey)
+	maps.Copy(httpReq.Header, req.Header)
+
+	defaults := map[string]string{
+		"Accept":            c.contentType,
+		"Accept-Encoding":   c.encodingType,
+		"Content-Encoding":  c.encodingType,
+		"Content-Type":      c.contentType,
+		"Dt-Client-Version": Version,
+		"User-Agent":        DefaultUserAgent,
 	}
 
-	req.Header.Add("Accept", c.contentType)
-	req.Header.Add("Accept-Encoding", c.encodingType)
-	req.Header.Add("Content-Encoding", c.encodingType)
-	req.Header.Add("Content-Type", c.contentType)
-	req.Header.Add("Dt-Client-Version", Version)
+	if c.apiKey != "" {
+		defaults["Authorization"] = "Bearer " + c.apiKey
+	}
 
-	if c.debug {
-		curl, cErr := http2curl.GetCurlCommand(req.Request)
-		if cErr == nil {
-			fmt.Println(curl.String())
+	for key, value := range defaults {
+		if httpReq.Header.Get(key) == "" {
+			httpReq.Header.Set(key, value)
 		}
 	}
 
-	res, err := c.client.Do(req)
+	debug := c.debugEnabled(ctx)
+	logURL := redactURL(endpointURL)
+
+	if debug {
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "api request",
+			slog.String("method", req.Method),
+			slog.String("url", logURL),
+			logHeaders(httpReq.Header),
+			logBody(req.Body, c.contentType),
+		)
+	}
+
+	start := time.Now()
+
+	res, err := c.client.Do(httpReq)
 	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+
+		if debug {
+			c.logger.LogAttrs(ctx, slog.LevelDebug, "api request failed",
+				slog.String("method", req.Method),
+				slog.String("url", logURL),
+				slog.Duration("duration", time.Since(start)),
+				slog.Any("error", err),
+			)
+		}
+
 		return nil, fmt.Errorf("make request: %w", err)
 	}
 	if res == nil {
@@ -142,45 +249,18 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, method string
 		return nil, fmt.Errorf("decode response body: %w", err)
 	}
 
-	if res.StatusCode >= http.StatusBadRequest {
-		if c.debug {
-			fmt.Println(string(resBody))
-		}
-
-		if len(resBody) > 0 {
-			resp := GenericResponse{}
-
-			switch res.Header.Get("Content-Type") {
-			case ContentTypeCBOR, "application/problem+cbor":
-				err = cbor.Unmarshal(resBody, &resp)
-			case ContentTypeJSON, "application/problem+json":
-				err = json.Unmarshal(resBody, &resp)
-			default:
-				err = errors.New("unknown content type")
-			}
-
-			if err == nil {
-				return nil, errors.New(resp.ToErrorString())
-			}
-		}
-
-		// Return the body as it may contain a useful error message.
-		return resBody, errors.New("request status code " + cast.ToString(res.StatusCode))
-	}
-
-	if len(resBody) > 0 && object != nil {
-		switch res.Header.Get("Content-Type") {
-		case ContentTypeCBOR:
-			err = cbor.Unmarshal(resBody, object)
-		case ContentTypeJSON:
-			err = json.Unmarshal(resBody, object)
-		}
-		if err != nil {
-			return nil, fmt.Errorf("request succeeded, couldn't unmarshal into object: %w", err)
-		}
+	if debug {
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "api response",
+			slog.String("method", req.Method),
+			slog.String("url", logURL),
+			slog.Int("status", res.StatusCode),
+			slog.Duration("duration", time.Since(start)),
+			logHeaders(res.Header),
+			logBody(resBody, res.Header.Get("Content-Type")),
+		)
 	}
 
-	return resBody, nil
+	return &Response{Body: resBody, Header: res.Header, StatusCode: res.StatusCode}, nil
 }
 
 // parseTag splits a struct tag like `foo:"name,omitempty,other"` into
@@ -3,17 +3,39 @@ package client
 import (
 	"context"
 	"fmt"
+	"iter"
+	"net/url"
 
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
 )
 
+// AcceptInvite registers the invited user with the given password, using the token from their invite email. It doesn't
+// need an API key, and returns the new user along with an API key for them.
+func (c *Client) AcceptInvite(ctx context.Context, token string, password string) (*model.UserWithAPIKey, error) {
+	body, err := c.marshal(map[string]map[string]string{"invite": {"password": password}})
+	if err != nil {
+		return nil, fmt.Errorf("marshal invite acceptance: %w", err)
+	}
+
+	var response struct {
+		APIKey *model.APIKey `json:"key"`
+		User   *model.User   `json:"user"`
+	}
+
+	if _, err = c.POST(withOperation(ctx, "AcceptInvite"), "invites/"+url.PathEscape(token)+"/accept", body, &response); err != nil {
+		return nil, fmt.Errorf("accept invite: %w", err)
+	}
+
+	return &model.UserWithAPIKey{User: response.User, APIKey: response.APIKey}, nil
+}
+
 func (c *Client) CreateInvite(ctx context.Context, invite *model.Invite) error {
 	body, err := c.marshal(map[string]*model.Invite{"invite": invite})
 	if err != nil {
 		return fmt.Errorf("marshal invite: %w", err)
 	}
 
-	if _, err = c.POST(ctx, "invites", body, nil); err != nil {
+	if _, err = c.POST(withOperation(ctx, "CreateInvite"), "invites", body, nil); err != nil {
 		return fmt.Errorf("create invite: %w", err)
 	}
 
@@ -21,7 +43,7 @@ func (c *Client) CreateInvite(ctx context.Context, invite *model.Invite) error {
 }
 
 func (c *Client) DeleteInvite(ctx context.Context, inviteID string) error {
-	if _, err := c.DELETE(ctx, "invites/"+inviteID, nil); err != nil {
+	if _, err := c.DELETE(withOperation(ctx, "DeleteInvite"), "invites/"+inviteID, nil); err != nil {
 		return fmt.Errorf("delete invite: %w", err)
 	}
 
@@ -29,27 +51,75 @@ func (c *Client) DeleteInvite(ctx context.Context, inviteID string) error {
 }
 
 func (c *Client) FindInvites(ctx context.Context, filter *model.InviteFilter) ([]*model.Invite, error) {
-	query := structToQueryParams(filter)
+	query := inviteFilterQuery(filter)
 
 	var response struct {
 		Invites []*model.Invite `json:"invites"`
 	}
 
-	if _, err := c.GET(ctx, "invites?"+query, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindInvites"), "invites?"+query, &response); err != nil {
 		return nil, fmt.Errorf("find invites: %w", err)
 	}
 
 	return response.Invites, nil
 }
 
+func (c *Client) FindInvitesPaged(ctx context.Context, filter *model.InviteFilter) (*Iterator[*model.Invite], error) {
+	fetch := func(ctx context.Context, pageToken string) ([]*model.Invite, string, error) {
+		q := inviteFilterQuery(filter)
+		if pageToken != "" {
+			q += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var resp struct {
+			Invites       []*model.Invite `json:"invites"`
+			NextPageToken string          `json:"nextPageToken"`
+		}
+
+		if _, err := c.GET(withOperation(ctx, "FindInvitesPaged"), "invites?"+q, &resp); err != nil {
+			return nil, "", fmt.Errorf("find invites: %w", err)
+		}
+
+		return resp.Invites, resp.NextPageToken, nil
+	}
+
+	return newIterator(ctx, fetch), nil
+}
+
+// Invites iterates over every invite matching the filter, fetching pages as needed.
+func (c *Client) Invites(ctx context.Context, filter *model.InviteFilter) iter.Seq2[*model.Invite, error] {
+	inviteIterator, _ := c.FindInvitesPaged(ctx, filter)
+
+	return inviteIterator.All()
+}
+
 func (c *Client) FindInviteByID(ctx context.Context, id string) (*model.Invite, error) {
 	var response struct {
 		Invite *model.Invite `json:"invite"`
 	}
 
-	if _, err := c.GET(ctx, "invites/"+id, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindInviteByID"), "invites/"+id, &response); err != nil {
 		return nil, fmt.Errorf("find invite: %w", err)
 	}
 
 	return response.Invite, nil
 }
+
+// inviteFilterQuery encodes filter as query parameters.
+func inviteFilterQuery(filter *model.InviteFilter) string {
+	query := structToQueryParams(filter)
+	if filter == nil {
+		return query
+	}
+
+	// The generated filter has no query tags for the invitee's details.
+	if filter.UserEmail != "" {
+		query += "&userEmail=" + url.QueryEscape(filter.UserEmail)
+	}
+
+	if filter.UserOrganizationID != "" {
+		query += "&userOrganizationID=" + url.QueryEscape(filter.UserOrganizationID)
+	}
+
+	return query
+}
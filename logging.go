@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	// maxLoggedBodySize is the largest body that's decoded into a log record; larger ones only have their size logged.
+	maxLoggedBodySize = 64 * 1024
+
+	redacted = "[REDACTED]"
+)
+
+var (
+	// cborLogDecoder decodes CBOR maps with string keys, so they log like JSON objects.
+	cborLogDecoder, _ = cbor.DecOptions{DefaultMapType: reflect.TypeFor[map[string]any]()}.DecMode()
+
+	// redactedHeaders hold credentials.
+	redactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key"}
+
+	// redactedFields are body fields holding secrets, wherever they appear (e.g. model.Login and model.UserUpdate
+	// passwords, and invite or password reset tokens).
+	redactedFields = []string{"apikey", "newpassword", "password", "secret", "token"}
+
+	// secretPaths match the endpoints that carry a token in their path.
+	secretPaths = []*regexp.Regexp{
+		regexp.MustCompile(`(auth/reset/)[^/?]+`),
+		regexp.MustCompile(`(invites/)[^/?]+(/accept)`),
+	}
+)
+
+// WithLogger logs every request and response (including retries) to logger as structured records at debug level, with
+// API keys, passwords and tokens redacted. Without it, nothing is logged unless WithDebug is enabled, in which case the
+// records go to stderr.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// newLogger returns the logger used when WithLogger isn't set.
+func newLogger(debug bool) *slog.Logger {
+	if !debug {
+		return slog.New(slog.DiscardHandler)
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// logBody returns a log attribute holding body decoded from contentType, with its secrets redacted.
+func logBody(body []byte, contentType string) slog.Attr {
+	if len(body) == 0 {
+		return slog.Attr{}
+	}
+
+	if len(body) > maxLoggedBodySize {
+		return slog.Int("bodySize", len(body))
+	}
+
+	var (
+		decoded any
+		err     error
+	)
+
+	contentType, _, _ = strings.Cut(contentType, ";")
+
+	switch contentType {
+	case ContentTypeCBOR, "application/problem+cbor":
+		err = cborLogDecoder.Unmarshal(body, &decoded)
+	case ContentTypeJSON, "application/problem+json":
+		err = json.Unmarshal(body, &decoded)
+	default:
+		return slog.Int("bodySize", len(body))
+	}
+
+	if err != nil {
+		return slog.Int("bodySize", len(body))
+	}
+
+	return slog.Any("body", redactValue(decoded, ""))
+}
+
+// logHeaders returns a log attribute holding header, with credentials redacted.
+func logHeaders(header http.Header) slog.Attr {
+	attrs := make([]slog.Attr, 0, len(header))
+
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		value := strings.Join(header.Values(name), ", ")
+		if slices.ContainsFunc(redactedHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+			value = redacted
+		}
+
+		attrs = append(attrs, slog.String(name, value))
+	}
+
+	return slog.Attr{Key: "headers", Value: slog.GroupValue(attrs...)}
+}
+
+// redactURL removes the tokens from a request URL's path and query.
+func redactURL(rawURL string) string {
+	for _, path := range secretPaths {
+		rawURL = path.ReplaceAllString(rawURL, "${1}"+redacted+"${2}")
+	}
+
+	base, rawQuery, ok := strings.Cut(rawURL, "?")
+	if !ok {
+		return rawURL
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?" + url.QueryEscape(redacted)
+	}
+
+	for name := range query {
+		if isSecretField(name, "") {
+			query.Set(name, redacted)
+		}
+	}
+
+	return base + "?" + strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+}
+
+// redactValue returns a copy of a decoded body with the values of secret fields replaced. parent is the name of the
+// field value was found in.
+func redactValue(value any, parent string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clean := make(map[string]any, len(v))
+
+		for name, field := range v {
+			if _, isString := field.(string); isString && isSecretField(name, parent) {
+				clean[name] = redacted
+				continue
+			}
+
+			clean[name] = redactValue(field, name)
+		}
+
+		return clean
+	case []any:
+		clean := make([]any, len(v))
+		for i, element := range v {
+			clean[i] = redactValue(element, parent)
+		}
+
+		return clean
+	}
+
+	return value
+}
+
+// isSecretField reports whether a field holds a secret. An API key's value is held in its "key" field, so that's only
+// treated as a secret inside the "key" (or "keys") wrapper the API uses for API keys.
+func isSecretField(name, parent string) bool {
+	name = strings.ToLower(name)
+
+	if slices.Contains(redactedFields, name) {
+		return true
+	}
+
+	return name == "key" && (parent == "key" || parent == "keys")
+}
+
+// logRetry logs each retry of a request. It's used as the retryablehttp client's RequestLogHook.
+func (c *Client) logRetry(_ retryablehttp.Logger, req *http.Request, attempt int) {
+	if attempt == 0 {
+		return
+	}
+
+	c.logger.LogAttrs(req.Context(), slog.LevelDebug, "retrying api request",
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL.String())),
+		slog.Int("attempt", attempt),
+	)
+}
+
+// debugEnabled reports whether debug records would be logged, so callers can skip building them.
+func (c *Client) debugEnabled(ctx context.Context) bool {
+	return c.logger.Enabled(ctx, slog.LevelDebug)
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+func (c *Client) FindDashboardMetrics(ctx context.Context) (*model.DashboardMetrics, error) {
+	var response struct {
+		Metrics *model.DashboardMetrics `json:"metrics"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindDashboardMetrics"), "metrics", &response); err != nil {
+		return nil, fmt.Errorf("find dashboard metrics: %w", err)
+	}
+
+	return response.Metrics, nil
+}
+
+func (c *Client) FindPublicMetrics(ctx context.Context) (*model.DashboardMetricsPublic, error) {
+	var response struct {
+		Metrics *model.DashboardMetricsPublic `json:"metrics"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindPublicMetrics"), "metrics/public", &response); err != nil {
+		return nil, fmt.Errorf("find public metrics: %w", err)
+	}
+
+	return response.Metrics, nil
+}
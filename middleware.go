@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent sent with every request, unless a middleware sets another.
+const DefaultUserAgent = "domain-trust-go/" + Version
+
+// RequestIDHeader carries the ID RequestIDMiddleware gives each request (the API echoes it back in its responses).
+const RequestIDHeader = "X-Request-Id"
+
+type (
+	// Request is an API call as seen by a Middleware. Headers set here take precedence over the client's defaults
+	// (e.g. a middleware can set its own Authorization header).
+	Request struct {
+		Header http.Header
+
+		// Body is the encoded (CBOR or JSON) request body, before it's compressed.
+		Body []byte
+
+		// Endpoint is the path (and query) relative to the client's base URL, e.g. "domains?limit=10".
+		Endpoint string
+		Method   string
+
+		// Operation is the name of the Client method that made the call, e.g. "FindDomains" (or "FindDomainsPaged" for
+		// each page fetched by an iterator). It's empty when GET, POST, etc. are called directly.
+		Operation string
+	}
+
+	// Response is the API's response as seen by a Middleware. Error statuses are returned as a Response too; the
+	// client turns them into an *APIError after the middleware chain has run.
+	Response struct {
+		Header http.Header
+
+		// Body is the decompressed (but still encoded) response body.
+		Body []byte
+
+		StatusCode int
+	}
+
+	// Handler sends a Request and returns its Response.
+	Handler func(ctx context.Context, req *Request) (*Response, error)
+
+	// Middleware wraps a Handler, e.g. to modify requests, or to inspect or replace responses.
+	Middleware func(next Handler) Handler
+
+	// operationKey is the context key holding the name of the Client method making a request.
+	operationKey struct{}
+)
+
+// WithMiddleware adds middlewares around every API call, in order: the first one is the outermost, so it sees the
+// request first and the response last. It can be used more than once; later middlewares are nested inside earlier
+// ones. Each call passes through the chain once; retries happen inside it.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		for _, middleware := range middlewares {
+			if middleware != nil {
+				c.middlewares = append(c.middlewares, middleware)
+			}
+		}
+	}
+}
+
+// withOperation returns a copy of ctx naming the Client method that's making a request, for Request.Operation.
+func withOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// operation returns the Client method name set by withOperation, if any.
+func operation(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// RequestIDMiddleware gives each request a random ID in the X-Request-Id header (unless it already has one), so it can
+// be traced in the API's logs.
+func RequestIDMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if req.Header.Get(RequestIDHeader) == "" {
+				req.Header.Set(RequestIDHeader, newUUID())
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// TimingMiddleware calls observe with each call's duration (including any retries), along with its response or error.
+func TimingMiddleware(observe func(req *Request, res *Response, err error, duration time.Duration)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+			res, err := next(ctx, req)
+
+			observe(req, res, err, time.Since(start))
+
+			return res, err
+		}
+	}
+}
+
+// UserAgentMiddleware appends suffix (e.g. "my-exporter/1.2") to the User-Agent header.
+func UserAgentMiddleware(suffix string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			userAgent := req.Header.Get("User-Agent")
+			if userAgent == "" {
+				userAgent = DefaultUserAgent
+			}
+
+			req.Header.Set("User-Agent", strings.TrimSpace(userAgent+" "+suffix))
+
+			return next(ctx, req)
+		}
+	}
+}
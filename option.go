@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+func (c *Client) FindOptions(ctx context.Context, filter *model.OptionFilter) ([]*model.Option, error) {
+	query := structToQueryParams(filter)
+
+	var response struct {
+		Options []*model.Option `json:"options"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindOptions"), "options?"+query, &response); err != nil {
+		return nil, fmt.Errorf("find options: %w", err)
+	}
+
+	return response.Options, nil
+}
+
+func (c *Client) FindOptionByKey(ctx context.Context, key string) (*model.Option, error) {
+	var response struct {
+		Option *model.Option `json:"option"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindOptionByKey"), "options/"+url.PathEscape(key), &response); err != nil {
+		return nil, fmt.Errorf("find option: %w", err)
+	}
+
+	return response.Option, nil
+}
+
+func (c *Client) UpdateOption(ctx context.Context, key string, update *model.OptionUpdate) (*model.Option, error) {
+	body, err := c.marshal(map[string]*model.OptionUpdate{"option": update})
+	if err != nil {
+		return nil, fmt.Errorf("marshal update: %w", err)
+	}
+
+	var response struct {
+		Option *model.Option `json:"option"`
+	}
+
+	if _, err = c.PATCH(withOperation(ctx, "UpdateOption"), "options/"+url.PathEscape(key), body, &response); err != nil {
+		return nil, fmt.Errorf("update option: %w", err)
+	}
+
+	return response.Option, nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+)
+
+func (c *Client) CreateOrganization(ctx context.Context, organization *model.Organization) (*model.Organization, error) {
+	body, err := c.marshal(map[string]*model.Organization{"organization": organization})
+	if err != nil {
+		return nil, fmt.Errorf("marshal organization: %w", err)
+	}
+
+	var response struct {
+		Organization *model.Organization `json:"organization"`
+	}
+
+	if _, err = c.POST(withOperation(ctx, "CreateOrganization"), "organizations", body, &response); err != nil {
+		return nil, fmt.Errorf("create organization: %w", err)
+	}
+
+	return response.Organization, nil
+}
+
+func (c *Client) DeleteOrganization(ctx context.Context, organizationID string) error {
+	if _, err := c.DELETE(withOperation(ctx, "DeleteOrganization"), "organizations/"+organizationID, nil); err != nil {
+		return fmt.Errorf("delete organization: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) FindOrganizations(ctx context.Context, filter *model.OrganizationFilter) ([]*model.Organization, error) {
+	query := structToQueryParams(filter)
+
+	var response struct {
+		Organizations []*model.Organization `json:"organizations"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindOrganizations"), "organizations?"+query, &response); err != nil {
+		return nil, fmt.Errorf("find organizations: %w", err)
+	}
+
+	return response.Organizations, nil
+}
+
+func (c *Client) FindOrganizationsPaged(ctx context.Context, filter *model.OrganizationFilter) (*Iterator[*model.Organization], error) {
+	fetch := func(ctx context.Context, pageToken string) ([]*model.Organization, string, error) {
+		q := structToQueryParams(filter)
+		if pageToken != "" {
+			q += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var resp struct {
+			Organizations []*model.Organization `json:"organizations"`
+			NextPageToken string                `json:"nextPageToken"`
+		}
+
+		if _, err := c.GET(withOperation(ctx, "FindOrganizationsPaged"), "organizations?"+q, &resp); err != nil {
+			return nil, "", fmt.Errorf("find organizations: %w", err)
+		}
+
+		return resp.Organizations, resp.NextPageToken, nil
+	}
+
+	return newIterator(ctx, fetch), nil
+}
+
+func (c *Client) FindOrganizationByID(ctx context.Context, id string) (*model.Organization, error) {
+	var response struct {
+		Organization *model.Organization `json:"organization"`
+	}
+
+	if _, err := c.GET(withOperation(ctx, "FindOrganizationByID"), "organizations/"+id, &response); err != nil {
+		return nil, fmt.Errorf("find organization: %w", err)
+	}
+
+	return response.Organization, nil
+}
+
+// Organizations iterates over every organization matching the filter, fetching pages as needed.
+func (c *Client) Organizations(ctx context.Context, filter *model.OrganizationFilter) iter.Seq2[*model.Organization, error] {
+	organizationIterator, _ := c.FindOrganizationsPaged(ctx, filter)
+
+	return organizationIterator.All()
+}
+
+func (c *Client) UpdateOrganization(ctx context.Context, id string, update *model.OrganizationUpdate) (*model.Organization, error) {
+	body, err := c.marshal(map[string]*model.OrganizationUpdate{"organization": update})
+	if err != nil {
+		return nil, fmt.Errorf("marshal update: %w", err)
+	}
+
+	var response struct {
+		Organization *model.Organization `json:"organization"`
+	}
+
+	if _, err = c.PATCH(withOperation(ctx, "UpdateOrganization"), "organizations/"+id, body, &response); err != nil {
+		return nil, fmt.Errorf("update organization: %w", err)
+	}
+
+	return response.Organization, nil
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"iter"
 )
 
 type (
@@ -12,14 +13,44 @@ type (
 		fetchPage PageFetcher[T]
 		finished  bool
 		index     int
+		lastPage  bool
 		nextToken string
 		page      []T
+		pageToken string
 	}
 
 	// PageFetcher fetches one page of items and returns items, nextPageToken, and an error.
 	PageFetcher[T any] func(ctx context.Context, pageToken string) ([]T, string, error)
 )
 
+func newIterator[T any](ctx context.Context, fetch PageFetcher[T]) *Iterator[T] {
+	// Initialize iterator (fetch first page lazily).
+	return &Iterator[T]{
+		ctx:       ctx,
+		fetchPage: fetch,
+		index:     0,
+		nextToken: "",
+		page:      nil,
+	}
+}
+
+// All returns a range-over-func adapter for the iterator, e.g. `for d, err := range it.All()`. Pages are fetched lazily,
+// so breaking out of the loop stops any further requests. A failed page fetch is yielded once as a non-nil error.
+func (it *Iterator[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for it.Next() {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
 // Err returns any error that occurred during iteration.
 func (it *Iterator[T]) Err() error {
 	return it.err
@@ -33,13 +64,25 @@ func (it *Iterator[T]) Next() bool {
 
 	// if no page or exhausted, fetch
 	if it.page == nil || it.index >= len(it.page) {
-		it.page, it.nextToken, it.err = it.fetchPage(it.ctx, it.nextToken)
+		// An empty next page token means the previous page was the last one.
+		if it.lastPage {
+			it.finished = true
+			return false
+		}
 
-		if it.err != nil {
+		page, nextToken, err := it.fetchPage(it.ctx, it.nextToken)
+		if err != nil {
+			// Keep nextToken intact, so NextPageToken() points at the page that failed.
+			it.err = err
 			it.finished = true
 			return false
 		}
 
+		it.lastPage = nextToken == ""
+		it.page = page
+		it.pageToken = it.nextToken
+		it.nextToken = nextToken
+
 		if len(it.page) == 0 {
 			it.finished = true
 			return false
@@ -53,6 +96,36 @@ func (it *Iterator[T]) Next() bool {
 	return true
 }
 
+// NextPageToken returns the token of the page that will be fetched once the current one is exhausted. It's empty once
+// the last page has been loaded. After a failed fetch, it holds the token of the page that failed.
+func (it *Iterator[T]) NextPageToken() string {
+	if it.lastPage {
+		return ""
+	}
+
+	return it.nextToken
+}
+
+// PageDone reports whether every item in the currently loaded page has been returned by Value.
+func (it *Iterator[T]) PageDone() bool {
+	return it.index >= len(it.page)
+}
+
+// PageToken returns the token that was used to fetch the currently loaded page (empty for the first page).
+func (it *Iterator[T]) PageToken() string {
+	return it.pageToken
+}
+
+// ResumeFrom starts the iterator at a previously saved page token (see NextPageToken) instead of the first page. It must
+// be called before the first call to Next.
+func (it *Iterator[T]) ResumeFrom(pageToken string) *Iterator[T] {
+	if it.page == nil && !it.finished {
+		it.nextToken = pageToken
+	}
+
+	return it
+}
+
 // Value returns the current element.
 func (it *Iterator[T]) Value() T {
 	return it.page[it.index-1]
@@ -0,0 +1,309 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reasons a request was held back by the rate limiter, reported in RateLimitEvent.Reason.
+const (
+	// RateLimitReasonBucket means the client's own token bucket was empty.
+	RateLimitReasonBucket = "bucket"
+
+	// RateLimitReasonQuota means the server reported that no requests remain until its quota resets.
+	RateLimitReasonQuota = "quota"
+
+	// RateLimitReasonRetryAfter means the server asked the client to back off with a Retry-After header.
+	RateLimitReasonRetryAfter = "retry-after"
+)
+
+// unixTimestampThreshold separates reset headers holding a Unix timestamp from those holding a number of seconds.
+const unixTimestampThreshold = 1_000_000_000
+
+type (
+	// RateLimitEvent describes a request the rate limiter held back.
+	RateLimitEvent struct {
+		// Reset is when the server's quota resets, if it reported one.
+		Reset time.Time
+
+		Method string
+		Reason string
+
+		// URL is the request's URL, with any invite or password reset token redacted.
+		URL string
+
+		// Wait is how long the request is held back for.
+		Wait time.Duration
+
+		// Limit and Remaining are the server's last reported quota, or -1 if it hasn't reported one.
+		Limit     int
+		Remaining int
+	}
+
+	// rateLimiter is a token bucket shared by every request made through a Client. Its rate drops to whatever the
+	// server's remaining quota allows until the quota resets, and it holds every request back while the server says
+	// no requests remain or has sent a Retry-After.
+	rateLimiter struct {
+		blockedUntil time.Time
+		hook         func(RateLimitEvent)
+		last         time.Time
+		quotaReset   time.Time
+		blockReason  string
+		burst        float64
+		limit        int
+		quotaRate    float64
+		rate         float64
+		remaining    int
+		tokens       float64
+		mu           sync.Mutex
+	}
+
+	rateLimitTransport struct {
+		limiter *rateLimiter
+		next    http.RoundTripper
+	}
+)
+
+// WithRateLimit throttles requests with a token bucket shared by every call on the client, allowing up to burst requests
+// at once and refilling at requestsPerSecond (a rate of 0 or less leaves only the server's limits in place). The bucket
+// adapts to the server's X-RateLimit-Remaining/X-RateLimit-Reset (or RateLimit-*) headers, spreading the remaining
+// quota over the time left until it resets, and holds callers back while the quota is exhausted or a Retry-After is in
+// effect. Every request is throttled, including retries. Use WithRateLimitHook to observe throttling.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		if requestsPerSecond <= 0 {
+			requestsPerSecond = math.Inf(1)
+		}
+
+		c.rateLimiter = &rateLimiter{
+			burst:     float64(max(burst, 1)),
+			limit:     -1,
+			rate:      requestsPerSecond,
+			remaining: -1,
+			tokens:    float64(max(burst, 1)),
+		}
+	}
+}
+
+// WithRateLimitHook sets a function that's called whenever the rate limiter (see WithRateLimit) holds a request back.
+// It's called from the goroutine making the request, before it waits.
+func WithRateLimitHook(hook func(RateLimitEvent)) Option {
+	return func(c *Client) {
+		c.rateLimitHook = hook
+	}
+}
+
+// RoundTrip waits for capacity, sends the request and updates the limiter from the response's headers.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context(), req); err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.limiter.observe(res)
+	}
+
+	return res, err
+}
+
+// wrap returns a copy of client whose transport goes through the limiter.
+func (l *rateLimiter) wrap(client *http.Client) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	wrapped := *client
+	wrapped.Transport = &rateLimitTransport{limiter: l, next: next}
+
+	return &wrapped
+}
+
+// observe updates the limiter from the rate limit headers of a response.
+func (l *rateLimiter) observe(res *http.Response) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit, ok := rateLimitHeader(res.Header, "Limit"); ok {
+		l.limit = int(limit)
+	}
+
+	reset, hasReset := time.Time{}, false
+	if value, ok := rateLimitHeader(res.Header, "Reset"); ok {
+		hasReset = true
+
+		if value >= unixTimestampThreshold {
+			reset = time.Unix(int64(value), 0)
+		} else {
+			reset = now.Add(time.Duration(value * float64(time.Second)))
+		}
+
+		l.quotaReset = reset
+	}
+
+	if remaining, ok := rateLimitHeader(res.Header, "Remaining"); ok {
+		l.remaining = int(remaining)
+
+		switch {
+		case remaining < 1 && hasReset:
+			l.block(reset, RateLimitReasonQuota)
+		case hasReset && reset.After(now):
+			// Spread what's left of the quota over the time until it resets, and don't allow a burst bigger than it.
+			l.quotaRate = remaining / reset.Sub(now).Seconds()
+			l.tokens = min(l.tokens, remaining)
+		}
+	}
+
+	if wait, ok := retryAfter(res.Header.Get("Retry-After"), now); ok {
+		l.block(now.Add(wait), RateLimitReasonRetryAfter)
+	}
+}
+
+// block holds every request back until the given time.
+func (l *rateLimiter) block(until time.Time, reason string) {
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+		l.blockReason = reason
+	}
+}
+
+// wait blocks until the limiter allows another request, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, req *http.Request) error {
+	reserved := false
+
+	// A token reserved from the bucket is ours once its wait is over, but the server may have asked for a pause in the
+	// meantime, so keep checking until nothing holds the request back.
+	for {
+		delay, event, took := l.reserve(req, reserved)
+		reserved = reserved || took
+
+		if delay <= 0 {
+			return nil
+		}
+
+		if l.hook != nil {
+			l.hook(event)
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			if reserved {
+				l.release()
+			}
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// release returns a reserved token that wasn't used.
+func (l *rateLimiter) release() {
+	l.mu.Lock()
+	l.tokens = min(l.burst, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// reserve takes a token from the bucket (unless reserved says the caller already holds one), and returns how long the
+// caller has to wait before sending its request. Tokens are handed out in order, so the bucket can go into debt: each
+// waiting caller is told exactly when its token becomes available. It also reports whether a token was taken.
+func (l *rateLimiter) reserve(req *http.Request, reserved bool) (time.Duration, RateLimitEvent, bool) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event := RateLimitEvent{
+		Limit:     l.limit,
+		Method:    req.Method,
+		Remaining: l.remaining,
+		Reset:     l.quotaReset,
+		URL:       redactURL(req.URL.String()),
+	}
+
+	if now.Before(l.blockedUntil) {
+		event.Reason = l.blockReason
+		event.Wait = l.blockedUntil.Sub(now)
+
+		return event.Wait, event, false
+	}
+
+	if reserved {
+		return 0, event, false
+	}
+
+	rate := l.rate
+	if l.quotaRate > 0 && now.Before(l.quotaReset) {
+		rate = min(rate, l.quotaRate)
+	} else if l.quotaRate > 0 {
+		// The quota has reset, so go back to the configured rate.
+		l.quotaRate = 0
+		l.tokens = max(l.tokens, l.burst)
+	}
+
+	switch {
+	case math.IsInf(rate, 1):
+		l.tokens = l.burst
+	case !l.last.IsZero():
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0, event, true
+	}
+
+	event.Reason = RateLimitReasonBucket
+	event.Wait = time.Duration(-l.tokens / rate * float64(time.Second))
+
+	return event.Wait, event, true
+}
+
+// rateLimitHeader reads a numeric X-RateLimit-* header, falling back to the IETF draft's RateLimit-* form.
+func rateLimitHeader(header http.Header, name string) (float64, bool) {
+	value := header.Get("X-RateLimit-" + name)
+	if value == "" {
+		value = header.Get("RateLimit-" + name)
+	}
+
+	if value == "" {
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, false
+	}
+
+	return number, true
+}
+
+// retryAfter parses a Retry-After header, which holds either a number of seconds or an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now), date.After(now)
+	}
+
+	return 0, false
+}
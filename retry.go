@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	DefaultRetryAttempts   = 5
+	DefaultRetryMaxBackoff = 30 * time.Second
+	DefaultRetryMinBackoff = time.Second
+
+	// IdempotencyKeyHeader carries the key that lets the API recognize a retried write it has already processed.
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// DefaultRetryableStatusCodes are the response statuses that are retried unless a RetryPolicy says otherwise.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type (
+	// RetryPolicy controls how failed requests are retried. Zero fields fall back to the defaults.
+	RetryPolicy struct {
+		// Backoff returns how long to wait before the given retry (starting at 0). It defaults to an exponential
+		// backoff between MinBackoff and MaxBackoff that honors Retry-After on 429 and 503 responses. Any
+		// retryablehttp.Backoff (e.g. retryablehttp.LinearJitterBackoff) can be used.
+		Backoff func(minBackoff, maxBackoff time.Duration, attempt int, res *http.Response) time.Duration
+
+		// MethodAttempts overrides MaxAttempts for specific HTTP methods, e.g. {"POST": 1} to never retry POSTs.
+		MethodAttempts map[string]int
+
+		// RetryableStatusCodes lists the response statuses worth retrying (DefaultRetryableStatusCodes if nil).
+		// Requests that fail without a response (e.g. a connection reset) are always retried, unless the error is
+		// permanent (such as an untrusted certificate).
+		RetryableStatusCodes []int
+
+		// MaxAttempts is the total number of attempts for each call, including the first (DefaultRetryAttempts if 0).
+		// Set it to 1 to disable retries.
+		MaxAttempts int
+
+		MaxBackoff time.Duration
+		MinBackoff time.Duration
+	}
+
+	// idempotencyKeyKey is the context key holding an idempotency key chosen by the caller (see withIdempotencyKey).
+	idempotencyKeyKey struct{}
+
+	// retryStateKey is the context key holding a call's retryState.
+	retryStateKey struct{}
+
+	// retryState tracks the attempts made by a single call.
+	retryState struct {
+		attempts    int
+		maxAttempts int
+		retries     int
+	}
+)
+
+// WithRetryPolicy replaces the default retry policy. Mutating requests (POST, PATCH, PUT and DELETE) that may be
+// retried carry an Idempotency-Key header, generated once per call and kept the same across its attempts, so the API
+// can tell a retry from a new request.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// attempts returns the total number of attempts allowed for method.
+func (p *RetryPolicy) attempts(method string) int {
+	if attempts, ok := p.MethodAttempts[strings.ToUpper(method)]; ok {
+		return max(attempts, 1)
+	}
+
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+
+	return DefaultRetryAttempts
+}
+
+// apply configures the retryablehttp client to follow the policy.
+func (p *RetryPolicy) apply(client *retryablehttp.Client) {
+	maxAttempts := p.attempts("")
+	for _, attempts := range p.MethodAttempts {
+		maxAttempts = max(maxAttempts, attempts)
+	}
+
+	client.RetryMax = maxAttempts - 1
+	client.RetryWaitMin = DefaultRetryMinBackoff
+	client.RetryWaitMax = DefaultRetryMaxBackoff
+	client.Backoff = retryablehttp.DefaultBackoff
+	client.CheckRetry = p.checkRetry
+
+	if p.MinBackoff > 0 {
+		client.RetryWaitMin = p.MinBackoff
+	}
+
+	if p.MaxBackoff > 0 {
+		client.RetryWaitMax = p.MaxBackoff
+	}
+
+	if p.Backoff != nil {
+		client.Backoff = p.Backoff
+	}
+}
+
+// checkRetry decides whether to retry a request, within the attempts allowed for its call.
+func (p *RetryPolicy) checkRetry(ctx context.Context, res *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	if err != nil {
+		// Let retryablehttp weed out the permanent errors (bad certificates, too many redirects, etc.).
+		if retry, rErr := retryablehttp.ErrorPropagatedRetryPolicy(ctx, nil, err); !retry {
+			return false, rErr
+		}
+	} else {
+		codes := p.RetryableStatusCodes
+		if codes == nil {
+			codes = DefaultRetryableStatusCodes
+		}
+
+		if !slices.Contains(codes, res.StatusCode) {
+			return false, nil
+		}
+	}
+
+	if state, ok := ctx.Value(retryStateKey{}).(*retryState); ok {
+		state.attempts++
+
+		if state.attempts >= state.maxAttempts {
+			return false, nil
+		}
+
+		state.retries++
+	}
+
+	return true, nil
+}
+
+// RetryCount returns how many times the API call carried by ctx has been retried so far. It's meant for middlewares
+// (see WithMiddleware), which can call it with the context they're given once the next handler returns.
+func RetryCount(ctx context.Context) int {
+	if state, ok := ctx.Value(retryStateKey{}).(*retryState); ok {
+		return state.retries
+	}
+
+	return 0
+}
+
+// withIdempotencyKey returns a copy of ctx whose mutating requests carry key as their Idempotency-Key, instead of one
+// generated per call. It lets callers that retry a write themselves (like BulkCreateDomains) send every attempt under
+// the same key, so the API can recognize a write it has already committed.
+func withIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// isMutating reports whether requests with the given method change data on the server.
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodDelete, http.MethodPatch, http.MethodPost, http.MethodPut:
+		return true
+	}
+
+	return false
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // Never returns an error.
+
+	b[6] = (b[6] & 0x0f) | 0x40 //nolint:mnd // Version 4.
+	b[8] = (b[8] & 0x3f) | 0x80 //nolint:mnd // RFC 4122 variant.
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
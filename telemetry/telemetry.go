@@ -0,0 +1,386 @@
+// Package telemetry instruments the Domain Trust client with OpenTelemetry. Its Middleware creates a client span for
+// each API call (named after the client method, e.g. "FindDomains"), propagates the W3C trace context to the API, and
+// records latency, payload size and error metrics:
+//
+//	c := dt.New(apiKey, dt.WithMiddleware(telemetry.Middleware()))
+//
+// By default, it uses the global tracer provider, meter provider and propagator (see go.opentelemetry.io/otel). Pass
+// WithTracerProvider, WithMeterProvider and WithPropagator to use others, e.g. the SDK's in-memory exporters in tests.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	dt "github.com/globalcyberalliance/domain-trust-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans and metrics this package records.
+const ScopeName = "github.com/globalcyberalliance/domain-trust-go/v2/telemetry"
+
+// Attribute keys set on spans (and, where they're low-cardinality, metrics).
+const (
+	AttributeErrorType           = attribute.Key("error.type")
+	AttributeHasNextPageToken    = attribute.Key("dt.response.next_page_token")
+	AttributeHasPageToken        = attribute.Key("dt.request.page_token")
+	AttributeHTTPMethod          = attribute.Key("http.request.method")
+	AttributeHTTPResendCount     = attribute.Key("http.request.resend_count")
+	AttributeHTTPStatusCode      = attribute.Key("http.response.status_code")
+	AttributeOperation           = attribute.Key("dt.operation")
+	AttributeRequestItemsPrefix  = "dt.request.items."
+	AttributeResponseItemsPrefix = "dt.response.items."
+)
+
+type (
+	// Option configures the Middleware.
+	Option func(*config)
+
+	config struct {
+		meterProvider  metric.MeterProvider
+		propagator     propagation.TextMapPropagator
+		tracerProvider trace.TracerProvider
+	}
+
+	instruments struct {
+		duration     metric.Float64Histogram
+		errors       metric.Int64Counter
+		requestSize  metric.Int64Histogram
+		responseSize metric.Int64Histogram
+	}
+)
+
+// WithMeterProvider sets the meter provider used to record metrics (otel.GetMeterProvider by default).
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		if provider != nil {
+			c.meterProvider = provider
+		}
+	}
+}
+
+// WithPropagator sets the propagator used to inject the trace context into requests (otel.GetTextMapPropagator by
+// default, or W3C trace context and baggage if no global propagator is set).
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		if propagator != nil {
+			c.propagator = propagator
+		}
+	}
+}
+
+// WithTracerProvider sets the tracer provider used to create spans (otel.GetTracerProvider by default).
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		if provider != nil {
+			c.tracerProvider = provider
+		}
+	}
+}
+
+// Middleware returns a client middleware (see dt.WithMiddleware) that traces each API call and records its metrics:
+//
+//   - dt.client.request.duration: call latency in seconds, including retries.
+//   - dt.client.request.body.size and dt.client.response.body.size: payload sizes in bytes (before compression).
+//   - dt.client.request.errors: calls that failed, or got an error status, by error.type.
+//
+// Each span records the HTTP method and status, the number of retries, whether a page token was sent and a next page
+// token returned, and the number of items in each list in the request and response bodies (e.g.
+// dt.response.items.domains). Add it before other middlewares, so its span covers them.
+func Middleware(opts ...Option) dt.Middleware {
+	cfg := config{
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+		tracerProvider: otel.GetTracerProvider(),
+	}
+
+	// The global propagator does nothing until one is set; trace context should still reach the API.
+	if len(cfg.propagator.Fields()) == 0 {
+		cfg.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tracer := cfg.tracerProvider.Tracer(ScopeName, trace.WithInstrumentationVersion(dt.Version))
+	inst := newInstruments(cfg.meterProvider.Meter(ScopeName, metric.WithInstrumentationVersion(dt.Version)))
+
+	return func(next dt.Handler) dt.Handler {
+		return func(ctx context.Context, req *dt.Request) (*dt.Response, error) {
+			// Endpoints hold resource IDs, so calls made without a Client method are only named by their HTTP method,
+			// keeping span names and metric attributes low-cardinality.
+			operation := req.Operation
+			if operation == "" {
+				operation = req.Method
+			}
+
+			ctx, span := tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+				AttributeOperation.String(operation),
+				AttributeHTTPMethod.String(req.Method),
+				AttributeHasPageToken.Bool(hasPageToken(req.Endpoint)),
+			))
+			defer span.End()
+
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			if span.IsRecording() {
+				span.SetAttributes(itemCounts(AttributeRequestItemsPrefix, req.Body, req.Header.Get("Content-Type"))...)
+			}
+
+			start := time.Now()
+			res, err := next(ctx, req)
+			duration := time.Since(start)
+
+			metricAttrs := []attribute.KeyValue{AttributeOperation.String(operation), AttributeHTTPMethod.String(req.Method)}
+			span.SetAttributes(AttributeHTTPResendCount.Int(dt.RetryCount(ctx)))
+
+			var errorType string
+
+			switch {
+			case err != nil:
+				errorType = errorTypeOf(err)
+
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			default:
+				metricAttrs = append(metricAttrs, AttributeHTTPStatusCode.Int(res.StatusCode))
+				span.SetAttributes(AttributeHTTPStatusCode.Int(res.StatusCode))
+
+				if res.StatusCode >= 400 { //nolint:mnd // Client and server errors.
+					errorType = strconv.Itoa(res.StatusCode)
+					span.SetStatus(codes.Error, "HTTP "+errorType)
+				}
+
+				if span.IsRecording() {
+					span.SetAttributes(responseAttributes(res)...)
+				}
+
+				inst.responseSize.Record(ctx, int64(len(res.Body)), metric.WithAttributes(metricAttrs...))
+			}
+
+			if errorType != "" {
+				metricAttrs = append(metricAttrs, AttributeErrorType.String(errorType))
+				span.SetAttributes(AttributeErrorType.String(errorType))
+				inst.errors.Add(ctx, 1, metric.WithAttributes(metricAttrs...))
+			}
+
+			inst.duration.Record(ctx, duration.Seconds(), metric.WithAttributes(metricAttrs...))
+			inst.requestSize.Record(ctx, int64(len(req.Body)), metric.WithAttributes(metricAttrs...))
+
+			return res, err
+		}
+	}
+}
+
+// newInstruments creates the metric instruments. Errors only come from invalid instrument names, and still return a
+// usable (no-op) instrument, so they're ignored.
+func newInstruments(meter metric.Meter) *instruments {
+	var inst instruments
+
+	inst.duration, _ = meter.Float64Histogram("dt.client.request.duration",
+		metric.WithDescription("Duration of Domain Trust API calls, including retries"), metric.WithUnit("s"))
+	inst.errors, _ = meter.Int64Counter("dt.client.request.errors",
+		metric.WithDescription("Domain Trust API calls that failed or returned an error status"), metric.WithUnit("{call}"))
+	inst.requestSize, _ = meter.Int64Histogram("dt.client.request.body.size",
+		metric.WithDescription("Size of Domain Trust API request bodies, before compression"), metric.WithUnit("By"))
+	inst.responseSize, _ = meter.Int64Histogram("dt.client.response.body.size",
+		metric.WithDescription("Size of Domain Trust API response bodies, after decompression"), metric.WithUnit("By"))
+
+	return &inst
+}
+
+// responseAttributes returns the item counts of a response body, and whether it holds a next page token.
+func responseAttributes(res *dt.Response) []attribute.KeyValue {
+	fields := topLevelFields(res.Body, res.Header.Get("Content-Type"))
+
+	attrs := []attribute.KeyValue{AttributeHasNextPageToken.Bool(fields.hasNextPageToken())}
+
+	return append(attrs, fields.itemCounts(AttributeResponseItemsPrefix)...)
+}
+
+// itemCounts returns the item counts of a request body.
+func itemCounts(prefix string, body []byte, contentType string) []attribute.KeyValue {
+	return topLevelFields(body, contentType).itemCounts(prefix)
+}
+
+// rawFields are the still-encoded top-level fields of a JSON or CBOR object. Only the lists' lengths and the page
+// token are read from them, so the items themselves are never decoded.
+type rawFields struct {
+	cbor map[string]cbor.RawMessage
+	json map[string]json.RawMessage
+}
+
+// topLevelFields splits a JSON or CBOR object into its fields, returning no fields for anything else.
+func topLevelFields(body []byte, contentType string) rawFields {
+	var fields rawFields
+
+	if len(body) == 0 {
+		return fields
+	}
+
+	contentType, _, _ = strings.Cut(contentType, ";")
+
+	switch contentType {
+	case dt.ContentTypeCBOR:
+		_ = cbor.Unmarshal(body, &fields.cbor)
+	case dt.ContentTypeJSON:
+		_ = json.Unmarshal(body, &fields.json)
+	}
+
+	return fields
+}
+
+// hasNextPageToken reports whether the object holds a non-empty nextPageToken.
+func (f rawFields) hasNextPageToken() bool {
+	var token string
+
+	if raw, ok := f.cbor["nextPageToken"]; ok {
+		_ = cbor.Unmarshal(raw, &token)
+	} else if raw, ok := f.json["nextPageToken"]; ok {
+		_ = json.Unmarshal(raw, &token)
+	}
+
+	return token != ""
+}
+
+// itemCounts returns an attribute for each list in the object, holding its length (e.g. dt.response.items.domains=50).
+func (f rawFields) itemCounts(prefix string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	for name, raw := range f.cbor {
+		if count, ok := cborArrayLength(raw); ok {
+			attrs = append(attrs, attribute.Int(prefix+name, count))
+		}
+	}
+
+	for name, raw := range f.json {
+		if count, ok := jsonArrayLength(raw); ok {
+			attrs = append(attrs, attribute.Int(prefix+name, count))
+		}
+	}
+
+	return attrs
+}
+
+// cborArrayLength returns the length of an encoded CBOR array, read from its header.
+func cborArrayLength(raw cbor.RawMessage) (int, bool) {
+	const (
+		majorTypeArray = 4
+		indefinite     = 31
+		maxInlineValue = 23
+	)
+
+	if len(raw) == 0 || raw[0]>>5 != majorTypeArray {
+		return 0, false
+	}
+
+	info := int(raw[0] & 0x1f) //nolint:mnd // The header's additional information bits.
+
+	switch {
+	case info <= maxInlineValue:
+		return info, true
+	case info == indefinite:
+		// The length isn't encoded up front, so each item has to be found.
+		var items []cbor.RawMessage
+		if err := cbor.Unmarshal(raw, &items); err != nil {
+			return 0, false
+		}
+
+		return len(items), true
+	}
+
+	// The length follows the header in 1, 2, 4 or 8 bytes (additional information 24 to 27).
+	size := 1 << (info - maxInlineValue - 1)
+	if size > 8 || len(raw) < 1+size { //nolint:mnd // Lengths are at most 8 bytes.
+		return 0, false
+	}
+
+	var length uint64
+	for _, b := range raw[1 : 1+size] {
+		length = length<<8 | uint64(b) //nolint:mnd // Big-endian bytes.
+	}
+
+	return int(length), true //nolint:gosec // Bodies can't hold more items than an int can count.
+}
+
+// jsonArrayLength returns the length of an encoded JSON array, by counting the commas between its top-level items.
+func jsonArrayLength(raw json.RawMessage) (int, bool) {
+	if len(raw) == 0 || raw[0] != '[' {
+		return 0, false
+	}
+
+	var (
+		count, depth     int
+		escaped, inValue bool
+		inString         bool
+	)
+
+	for _, b := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			escaped = b == '\\'
+			inString = b != '"'
+		case b == '"':
+			inString = true
+			inValue = true
+		case b == '[' || b == '{':
+			if depth == 1 {
+				inValue = true
+			}
+
+			depth++
+		case b == ']' || b == '}':
+			depth--
+		case b == ',' && depth == 1:
+			count++
+		case depth == 1 && b != ' ' && b != '\t' && b != '\n' && b != '\r':
+			inValue = true
+		}
+	}
+
+	if inValue {
+		count++
+	}
+
+	return count, true
+}
+
+// errorTypeOf returns a low-cardinality error.type for an error: the HTTP status of an *dt.APIError, "timeout" or
+// "canceled" for context errors, and "_OTHER" for anything else, as the semantic conventions specify.
+func errorTypeOf(err error) string {
+	var apiErr *dt.APIError
+
+	switch {
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "_OTHER"
+	}
+}
+
+// hasPageToken reports whether an endpoint's query holds a page token.
+func hasPageToken(endpoint string) bool {
+	_, rawQuery, _ := strings.Cut(endpoint, "?")
+
+	query, err := url.ParseQuery(rawQuery)
+
+	return err == nil && query.Get("pageToken") != ""
+}
@@ -0,0 +1,323 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	dt "github.com/globalcyberalliance/domain-trust-go/v2"
+	"github.com/globalcyberalliance/domain-trust-go/v2/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testAPI serves two pages of domains (failing the first request once, so it's retried) and a 404 for anything else.
+// It records the traceparent header of every request.
+type testAPI struct {
+	traceparents []string
+	failed       bool
+	mu           sync.Mutex
+}
+
+func (a *testAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	a.traceparents = append(a.traceparents, r.Header.Get("Traceparent"))
+	retry := !a.failed
+	a.failed = true
+	a.mu.Unlock()
+
+	if r.URL.Path != "/domains" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if retry {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	page := map[string]any{
+		"domains":       []map[string]string{{"domain": "a.example"}, {"domain": "b.example"}},
+		"nextPageToken": "page2",
+	}
+	if r.URL.Query().Get("pageToken") == "page2" {
+		page = map[string]any{"domains": []map[string]string{{"domain": "c.example"}}}
+	}
+
+	body, err := cbor.Marshal(page)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", dt.ContentTypeCBOR)
+	_, _ = w.Write(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	api := &testAPI{}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	spans := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	c := dt.New("key",
+		dt.WithBaseURL(server.URL),
+		dt.WithRetryPolicy(dt.RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		dt.WithMiddleware(Middleware(WithTracerProvider(tracerProvider), WithMeterProvider(meterProvider))),
+	)
+
+	ctx := context.Background()
+
+	var domains int
+	for _, err := range c.Domains(ctx, &model.DomainFilter{}) {
+		if err != nil {
+			t.Fatalf("Domains() error = %v", err)
+		}
+
+		domains++
+	}
+
+	if domains != 3 {
+		t.Fatalf("Domains() returned %d domains, want 3", domains)
+	}
+
+	if _, err := c.FindDomainByID(ctx, "missing"); !errors.Is(err, dt.ErrNotFound) {
+		t.Fatalf("FindDomainByID() error = %v, want ErrNotFound", err)
+	}
+
+	got := spans.GetSpans()
+	if len(got) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(got))
+	}
+
+	tests := []struct {
+		name   string
+		status codes.Code
+		attrs  map[attribute.Key]attribute.Value
+	}{
+		{
+			name:   "FindDomainsPaged",
+			status: codes.Unset,
+			attrs: map[attribute.Key]attribute.Value{
+				AttributeHTTPStatusCode:                  attribute.IntValue(http.StatusOK),
+				AttributeHTTPResendCount:                 attribute.IntValue(1),
+				AttributeHasPageToken:                    attribute.BoolValue(false),
+				AttributeHasNextPageToken:                attribute.BoolValue(true),
+				AttributeResponseItemsPrefix + "domains": attribute.IntValue(2),
+				AttributeOperation:                       attribute.StringValue("FindDomainsPaged"),
+				AttributeHTTPMethod:                      attribute.StringValue(http.MethodGet),
+			},
+		},
+		{
+			name:   "FindDomainsPaged",
+			status: codes.Unset,
+			attrs: map[attribute.Key]attribute.Value{
+				AttributeHTTPStatusCode:                  attribute.IntValue(http.StatusOK),
+				AttributeHTTPResendCount:                 attribute.IntValue(0),
+				AttributeHasPageToken:                    attribute.BoolValue(true),
+				AttributeHasNextPageToken:                attribute.BoolValue(false),
+				AttributeResponseItemsPrefix + "domains": attribute.IntValue(1),
+			},
+		},
+		{
+			name:   "FindDomainByID",
+			status: codes.Error,
+			attrs: map[attribute.Key]attribute.Value{
+				AttributeHTTPStatusCode:  attribute.IntValue(http.StatusNotFound),
+				AttributeHTTPResendCount: attribute.IntValue(0),
+				AttributeErrorType:       attribute.StringValue("404"),
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		span := got[i]
+
+		if span.Name != tt.name {
+			t.Errorf("span %d name = %q, want %q", i, span.Name, tt.name)
+		}
+
+		if span.Status.Code != tt.status {
+			t.Errorf("span %d status = %v, want %v", i, span.Status.Code, tt.status)
+		}
+
+		attrs := attribute.NewSet(span.Attributes...)
+		for key, want := range tt.attrs {
+			if value, ok := attrs.Value(key); !ok || value != want {
+				t.Errorf("span %d attribute %s = %v, want %v", i, key, value.Emit(), want.Emit())
+			}
+		}
+	}
+
+	// The first page was sent twice (once more after the 503), with the same trace context.
+	wantTraceparents := []tracetest.SpanStub{got[0], got[0], got[1], got[2]}
+	if len(api.traceparents) != len(wantTraceparents) {
+		t.Fatalf("API received %d requests, want %d", len(api.traceparents), len(wantTraceparents))
+	}
+
+	for i, span := range wantTraceparents {
+		want := fmt.Sprintf("00-%s-%s-01", span.SpanContext.TraceID(), span.SpanContext.SpanID())
+		if api.traceparents[i] != want {
+			t.Errorf("request %d traceparent = %q, want %q", i, api.traceparents[i], want)
+		}
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &metrics); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	counts := make(map[string]uint64)
+
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					counts[m.Name] += point.Count
+				}
+			case metricdata.Histogram[int64]:
+				for _, point := range data.DataPoints {
+					counts[m.Name] += point.Count
+				}
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					counts[m.Name] += uint64(point.Value) //nolint:gosec // Counters aren't negative.
+				}
+			}
+		}
+	}
+
+	wantCounts := map[string]uint64{
+		"dt.client.request.duration":   3,
+		"dt.client.request.body.size":  3,
+		"dt.client.response.body.size": 3,
+		"dt.client.request.errors":     1,
+	}
+
+	for name, want := range wantCounts {
+		if counts[name] != want {
+			t.Errorf("metric %s recorded %d times, want %d", name, counts[name], want)
+		}
+	}
+}
+
+func TestMiddlewareDirectCall(t *testing.T) {
+	server := httptest.NewServer(&testAPI{})
+	t.Cleanup(server.Close)
+
+	spans := tracetest.NewInMemoryExporter()
+
+	c := dt.New("key",
+		dt.WithBaseURL(server.URL),
+		dt.WithMiddleware(Middleware(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))))),
+	)
+
+	// Calls made without a Client method are named after the HTTP method alone, never the endpoint and its IDs.
+	if _, err := c.GET(context.Background(), "organizations/019a0dd4-11a5-7477-91a8-538b1bc334e4", nil); !errors.Is(err, dt.ErrNotFound) {
+		t.Fatalf("GET() error = %v, want ErrNotFound", err)
+	}
+
+	got := spans.GetSpans()
+	if len(got) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(got))
+	}
+
+	if got[0].Name != http.MethodGet {
+		t.Errorf("span name = %q, want %q", got[0].Name, http.MethodGet)
+	}
+
+	attrs := attribute.NewSet(got[0].Attributes...)
+	if value, _ := attrs.Value(AttributeOperation); value.AsString() != http.MethodGet {
+		t.Errorf("span attribute %s = %q, want %q", AttributeOperation, value.AsString(), http.MethodGet)
+	}
+}
+
+func TestErrorTypeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: fmt.Errorf("send request: %w", &dt.APIError{StatusCode: http.StatusBadGateway}), want: "502"},
+		{err: fmt.Errorf("send request: %w", context.DeadlineExceeded), want: "timeout"},
+		{err: context.Canceled, want: "canceled"},
+		{err: fmt.Errorf("send request: %w", errors.New("connection refused")), want: "_OTHER"},
+	}
+
+	for _, tt := range tests {
+		if got := errorTypeOf(tt.err); got != tt.want {
+			t.Errorf("errorTypeOf(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestArrayLength(t *testing.T) {
+	encode := func(v any) []byte {
+		b, err := cbor.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return b
+	}
+
+	// An indefinite-length array of 3 items: [_ 1, 2, 3].
+	indefinite := []byte{0x9f, 0x01, 0x02, 0x03, 0xff}
+
+	cborTests := []struct {
+		name string
+		raw  []byte
+		want int
+		ok   bool
+	}{
+		{name: "empty", raw: encode([]int{}), want: 0, ok: true},
+		{name: "inline length", raw: encode(make([]int, 23)), want: 23, ok: true},
+		{name: "1-byte length", raw: encode(make([]int, 24)), want: 24, ok: true},
+		{name: "2-byte length", raw: encode(make([]int, 300)), want: 300, ok: true},
+		{name: "4-byte length", raw: encode(make([]int, 70000)), want: 70000, ok: true},
+		{name: "indefinite length", raw: indefinite, want: 3, ok: true},
+		{name: "not an array", raw: encode("domains"), ok: false},
+	}
+
+	for _, tt := range cborTests {
+		if got, ok := cborArrayLength(tt.raw); got != tt.want || ok != tt.ok {
+			t.Errorf("cborArrayLength(%s) = %d, %t, want %d, %t", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	jsonTests := []struct {
+		raw  string
+		want int
+		ok   bool
+	}{
+		{raw: `[]`, want: 0, ok: true},
+		{raw: `[ ]`, want: 0, ok: true},
+		{raw: `[1]`, want: 1, ok: true},
+		{raw: `[1, 2, 3]`, want: 3, ok: true},
+		{raw: `[{"a": [1, 2]}, {"b": "x,y"}]`, want: 2, ok: true},
+		{raw: `["a\"],", "b\\"]`, want: 2, ok: true},
+		{raw: `[[], []]`, want: 2, ok: true},
+		{raw: `{"a": 1}`, ok: false},
+		{raw: `"a"`, ok: false},
+	}
+
+	for _, tt := range jsonTests {
+		if got, ok := jsonArrayLength([]byte(tt.raw)); got != tt.want || ok != tt.ok {
+			t.Errorf("jsonArrayLength(%s) = %d, %t, want %d, %t", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
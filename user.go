@@ -3,12 +3,14 @@ package client
 import (
 	"context"
 	"fmt"
+	"iter"
+	"net/url"
 
 	"github.com/globalcyberalliance/domain-trust-go/v2/model"
 )
 
 func (c *Client) DeleteUser(ctx context.Context, userID string) error {
-	if _, err := c.DELETE(ctx, "users/"+userID, nil); err != nil {
+	if _, err := c.DELETE(withOperation(ctx, "DeleteUser"), "users/"+userID, nil); err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
 
@@ -20,7 +22,7 @@ func (c *Client) FindSessionUser(ctx context.Context) (*model.User, error) {
 		User *model.User `json:"user"`
 	}
 
-	if _, err := c.GET(ctx, "user", &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindSessionUser"), "user", &response); err != nil {
 		return nil, fmt.Errorf("find user: %w", err)
 	}
 
@@ -34,25 +36,54 @@ func (c *Client) FindUsers(ctx context.Context, filter *model.UserFilter) ([]*mo
 		Users []*model.User `json:"users"`
 	}
 
-	if _, err := c.GET(ctx, "users?"+query, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindUsers"), "users?"+query, &response); err != nil {
 		return nil, fmt.Errorf("find users: %w", err)
 	}
 
 	return response.Users, nil
 }
 
+func (c *Client) FindUsersPaged(ctx context.Context, filter *model.UserFilter) (*Iterator[*model.User], error) {
+	fetch := func(ctx context.Context, pageToken string) ([]*model.User, string, error) {
+		q := structToQueryParams(filter)
+		if pageToken != "" {
+			q += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var resp struct {
+			Users         []*model.User `json:"users"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+
+		if _, err := c.GET(withOperation(ctx, "FindUsersPaged"), "users?"+q, &resp); err != nil {
+			return nil, "", fmt.Errorf("find users: %w", err)
+		}
+
+		return resp.Users, resp.NextPageToken, nil
+	}
+
+	return newIterator(ctx, fetch), nil
+}
+
 func (c *Client) FindUserByID(ctx context.Context, id string) (*model.User, error) {
 	var response struct {
 		User *model.User `json:"user"`
 	}
 
-	if _, err := c.GET(ctx, "users/"+id, &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindUserByID"), "users/"+id, &response); err != nil {
 		return nil, fmt.Errorf("find user: %w", err)
 	}
 
 	return response.User, nil
 }
 
+// Users iterates over every user matching the filter, fetching pages as needed.
+func (c *Client) Users(ctx context.Context, filter *model.UserFilter) iter.Seq2[*model.User, error] {
+	userIterator, _ := c.FindUsersPaged(ctx, filter)
+
+	return userIterator.All()
+}
+
 func (c *Client) UpdateUser(ctx context.Context, id string, update *model.UserUpdate) (*model.User, error) {
 	body, err := c.marshal(map[string]*model.UserUpdate{"user": update})
 	if err != nil {
@@ -63,7 +94,7 @@ func (c *Client) UpdateUser(ctx context.Context, id string, update *model.UserUp
 		User *model.User `json:"user"`
 	}
 
-	if _, err = c.PATCH(ctx, "users/"+id, body, &response); err != nil {
+	if _, err = c.PATCH(withOperation(ctx, "UpdateUser"), "users/"+id, body, &response); err != nil {
 		return nil, fmt.Errorf("update user: %w", err)
 	}
 
@@ -10,7 +10,7 @@ func (c *Client) FindVersion(ctx context.Context) (string, error) {
 		Version string `json:"version"`
 	}
 
-	if _, err := c.GET(ctx, "version", &response); err != nil {
+	if _, err := c.GET(withOperation(ctx, "FindVersion"), "version", &response); err != nil {
 		return "", fmt.Errorf("find api Version: %w", err)
 	}
 